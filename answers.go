@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// MakeAnswers runs a reference solution on every test without an answer, and
+// makes its output the answer. Like the brute-force solution of stress, the
+// reference solution runs without limits, as it's trusted to be correct.
+func MakeAnswers(config GocfConfig, ref string) {
+	session := LoadCurrentSession(config)
+	if session.Interactor != "" {
+		fmt.Println("Answers can't be made for interactive tasks")
+		os.Exit(1)
+	}
+	var ids []int
+	for id := 1; FileExists(inPath(config, id)); id++ {
+		if FileNotExist(ansPath(config, id)) {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		fmt.Println("Every test has an answer already")
+		return
+	}
+
+	CleanTestDir(config, session)
+	fmt.Println("Compiling...")
+	program, err := prepareProgram(ref)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	refSession := session
+	refSession.TimeLimit, refSession.MemLimit, refSession.IdleLimit = 0, 0, 0
+
+	dir := config.SessionDir + "/" + TestPoolDir
+	outputFile := dir + "/answer.out"
+	failed := 0
+	for _, id := range ids {
+		result, stats := execute(refSession, program, dir+"/run-answers", inPath(config, id), outputFile)
+		if result != OK {
+			msg := result.String()
+			if cause, _ := crashCause(stats); result == RTE && cause != "" {
+				msg += " (" + cause + ")"
+			}
+			fmt.Printf("  Test #%d: %s\n", id, msg)
+			failed++
+			continue
+		}
+		if err := os.Rename(outputFile, ansPath(config, id)); err != nil {
+			fmt.Printf("  Test #%d: %v\n", id, err)
+			failed++
+			continue
+		}
+		fmt.Printf("  Test #%d: answer written\n", id)
+	}
+	os.Remove(outputFile)
+	if failed > 0 {
+		fmt.Println("Reference solution failed on", failed, "tests")
+		os.Exit(1)
+	}
+	fmt.Println(len(ids), "answers written")
+}
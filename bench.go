@@ -0,0 +1,190 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"os/exec"
+	"strconv"
+	"time"
+)
+
+const BenchDir string = "bench"
+
+// a complexity class to fit measurements against
+type complexityClass struct {
+	Name string
+	f    func(n float64) float64
+}
+
+var complexityClasses = []complexityClass{
+	{"O(1)", func(n float64) float64 { return 0 }},
+	{"O(log n)", func(n float64) float64 { return math.Log2(n) }},
+	{"O(n)", func(n float64) float64 { return n }},
+	{"O(n log n)", func(n float64) float64 { return n * math.Log2(n) }},
+	{"O(n²)", func(n float64) float64 { return n * n }},
+	{"O(n³)", func(n float64) float64 { return n * n * n }},
+	{"O(2ⁿ)", func(n float64) float64 { return math.Exp2(n) }},
+}
+
+// a more complex class must fit this much better to be chosen, as the
+// measurements are noisy
+const fitTolerance = 0.01
+
+// complexityFit models measurements y at sizes n as A + C·f(n), where A
+// accounts for what doesn't depend on n (e.g. starting the process).
+type complexityFit struct {
+	Class complexityClass
+	A, C  float64
+	Err   float64 // root mean square of the relative errors
+}
+
+func (fit complexityFit) At(n float64) float64 {
+	return fit.A + fit.C*fit.Class.f(n)
+}
+
+// fitClass fits measurements to a class by least squares on the relative
+// errors, so that small sizes count as much as large ones.
+func fitClass(class complexityClass, ns, ys []float64) (complexityFit, bool) {
+	var sw, swf, swff, swy, swfy float64
+	for i, n := range ns {
+		f := class.f(n)
+		if math.IsInf(f*f, 0) {
+			return complexityFit{}, false
+		}
+		w := 1 / math.Max(ys[i]*ys[i], 1e-12)
+		sw += w
+		swf += w * f
+		swff += w * f * f
+		swy += w * ys[i]
+		swfy += w * f * ys[i]
+	}
+	fit := complexityFit{Class: class}
+	if det := sw*swff - swf*swf; det > 1e-12*sw*swff {
+		fit.A = (swy*swff - swf*swfy) / det
+		fit.C = (sw*swfy - swf*swy) / det
+	}
+	if fit.C <= 0 {
+		fit.A, fit.C = swy/sw, 0
+	} else if fit.A < 0 {
+		fit.A, fit.C = 0, swfy/swff
+	}
+	for i, n := range ns {
+		e := (ys[i] - fit.At(n)) / math.Max(ys[i], 1e-6)
+		fit.Err += e * e
+	}
+	fit.Err = math.Sqrt(fit.Err / float64(len(ns)))
+	return fit, true
+}
+
+// fitComplexity returns the simplest class fitting the measurements best.
+func fitComplexity(ns, ys []float64) complexityFit {
+	var best complexityFit
+	for i, class := range complexityClasses {
+		fit, ok := fitClass(class, ns, ys)
+		if ok && (i == 0 || fit.Err < best.Err-fitTolerance) {
+			best = fit
+		}
+	}
+	return best
+}
+
+// describeFit tells the class of a fit and its extrapolation to the maximum n,
+// compared to the limit if there's one.
+func describeFit(fit complexityFit, max int, format func(float64) string, limit float64) (string, bool) {
+	expected := fit.At(float64(max))
+	desc := fmt.Sprintf("%s (fit error %.1f%%), ", fit.Class.Name, 100*fit.Err)
+	if math.IsInf(expected, 0) || expected > 1e18 {
+		desc += fmt.Sprintf("far too much at n=%d", max)
+	} else {
+		desc += fmt.Sprintf("about %s at n=%d", format(expected), max)
+	}
+	if limit <= 0 {
+		return desc, true
+	}
+	if expected > limit {
+		return desc + ", OVER the limit of " + format(limit), false
+	}
+	return desc + fmt.Sprintf(", %.0f%% of the limit of %s", 100*expected/limit, format(limit)), true
+}
+
+// Bench runs the work file on inputs of growing size, made by a generator
+// which receives n as its only argument, and fits its CPU time and memory to
+// common complexity classes. They are then extrapolated to the maximum n, to
+// tell whether the solution is fast enough before submitting it.
+func Bench(config GocfConfig, gen string, opts BenchOptions) {
+	session := LoadCurrentSession(config)
+	if session.Interactor != "" {
+		fmt.Println("Benchmarking is not supported for interactive tasks")
+		os.Exit(1)
+	}
+
+	fmt.Println("Removing test directory...")
+	CleanTestDir(config, session)
+	dir := config.SessionDir + "/" + TestPoolDir + "/" + BenchDir
+	os.MkdirAll(dir, os.ModePerm)
+	fmt.Println("Compiling...")
+	solution := Compile(config, session, false)
+	genProgram, err := prepareProgram(gen)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	inputFile := dir + "/bench.in"
+	outputFile := dir + "/bench.out"
+	var ns, times, memory []float64
+	fmt.Printf("%10s %10s %10s\n", "n", "cpu", "memory")
+	failed := false
+sizes:
+	for _, n := range opts.Sizes {
+		genArgs := append(append([]string{}, genProgram[1:]...), strconv.Itoa(n))
+		input, err := exec.Command(genProgram[0], genArgs...).Output()
+		if err != nil {
+			fmt.Println("Generator failed with n =", n, ":", err)
+			os.Exit(1)
+		}
+		ioutil.WriteFile(inputFile, input, os.ModePerm)
+
+		// keep the fastest run, the others being slowed down by the system
+		var best RunStats
+		for i := 0; i < opts.Repeat; i++ {
+			result, stats := execute(session, solution, dir+"/run-solution", inputFile, outputFile)
+			if result != OK {
+				// the larger sizes would fail too, fit what was measured so far
+				fmt.Printf("%10d %s\n", n, result)
+				if cause, _ := crashCause(stats); result == RTE && cause != "" {
+					fmt.Println("Cause:", cause)
+				}
+				failed = true
+				break sizes
+			}
+			if i == 0 || stats.CPUTime < best.CPUTime {
+				best.CPUTime = stats.CPUTime
+			}
+			if i == 0 || stats.Memory < best.Memory {
+				best.Memory = stats.Memory
+			}
+		}
+		fmt.Printf("%10d %9.3fs %10s\n", n, best.CPUTime.Seconds(), FormatMemory(best.Memory))
+		ns = append(ns, float64(n))
+		times = append(times, best.CPUTime.Seconds())
+		memory = append(memory, float64(best.Memory))
+	}
+
+	if len(ns) < 3 {
+		fmt.Println("Too few sizes measured to fit the complexity, try smaller ones with --sizes")
+		os.Exit(1)
+	}
+	formatTime := func(s float64) string { return fmt.Sprintf("%.3fs", s) }
+	formatMemory := func(b float64) string { return FormatMemory(int64(b)) }
+	timeLimit := (time.Duration(session.TimeLimit) * time.Millisecond).Seconds()
+	timeDesc, timeOk := describeFit(fitComplexity(ns, times), opts.Max, formatTime, timeLimit)
+	memDesc, memOk := describeFit(fitComplexity(ns, memory), opts.Max, formatMemory, float64(session.MemLimit))
+	fmt.Println("Time:  ", timeDesc)
+	fmt.Println("Memory:", memDesc)
+	if failed || !timeOk || !memOk {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestFitComplexity(t *testing.T) {
+	tests := []struct {
+		f     func(n float64) float64
+		class string
+	}{
+		{func(n float64) float64 { return 0.01 }, "O(1)"},
+		{func(n float64) float64 { return 0.01 + 1e-7*n }, "O(n)"},
+		{func(n float64) float64 { return 0.01 + 1e-8*n*math.Log2(n) }, "O(n log n)"},
+		{func(n float64) float64 { return 0.01 + 1e-10*n*n }, "O(n²)"},
+	}
+	ns := []float64{1000, 3000, 10000, 30000, 100000, 300000}
+	for _, test := range tests {
+		var ys []float64
+		for _, n := range ns {
+			ys = append(ys, test.f(n))
+		}
+		fit := fitComplexity(ns, ys)
+		if fit.Class.Name != test.class {
+			t.Errorf("fitComplexity() = %s; want %s", fit.Class.Name, test.class)
+			continue
+		}
+		if expected, at := test.f(1e6), fit.At(1e6); math.Abs(at-expected) > 0.01*expected {
+			t.Errorf("%s fit at n=1e6 = %g; want %g", test.class, at, expected)
+		}
+	}
+}
+
+func TestDescribeFit(t *testing.T) {
+	fit := complexityFit{Class: complexityClasses[2], A: 0.01, C: 1e-6, Err: 0.02}
+	format := func(s float64) string { return fmt.Sprintf("%.3fs", s) }
+	tests := []struct {
+		limit float64
+		desc  string
+		ok    bool
+	}{
+		{0, "O(n) (fit error 2.0%), about 0.110s at n=100000", true},
+		{1, "O(n) (fit error 2.0%), about 0.110s at n=100000, 11% of the limit of 1.000s", true},
+		{0.1, "O(n) (fit error 2.0%), about 0.110s at n=100000, OVER the limit of 0.100s", false},
+	}
+	for _, test := range tests {
+		desc, ok := describeFit(fit, 100000, format, test.limit)
+		if desc != test.desc || ok != test.ok {
+			t.Errorf("describeFit(limit %g) = %q, %v; want %q, %v", test.limit, desc, ok, test.desc, test.ok)
+		}
+	}
+}
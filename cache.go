@@ -0,0 +1,114 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// BuildCacheDir returns the directory where compiled programs are kept
+// between runs, so that unchanged sources are not built again.
+func BuildCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "gocf", "builds"), nil
+}
+
+// buildKey identifies a build by the source name and contents, the compile
+// command and the version of the compiler. The name matters, as the program
+// is named after it. The files next to the source which it can include (e.g.
+// testlib.h) are part of the key too.
+func (lang LanguageProfile) buildKey(src string, flags []string) (string, error) {
+	contents, err := ioutil.ReadFile(src)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	h.Write([]byte(lang.Name + "\x00"))
+	h.Write([]byte(strings.Join(expandCommand(lang.Compile, "", "", flags), "\x00") + "\x00"))
+	if len(lang.Version) > 0 {
+		version, err := exec.Command(lang.Version[0], lang.Version[1:]...).CombinedOutput()
+		if err != nil {
+			return "", err
+		}
+		h.Write(version)
+	}
+	h.Write([]byte(filepath.Base(src) + "\x00"))
+	h.Write(contents)
+	infos, _ := ioutil.ReadDir(filepath.Dir(src))
+	for _, info := range infos {
+		name := info.Name()
+		if info.IsDir() || name == filepath.Base(src) || !hasExtension(name, lang.Includes) {
+			continue
+		}
+		contents, err := ioutil.ReadFile(filepath.Join(filepath.Dir(src), name))
+		if err != nil {
+			return "", err
+		}
+		h.Write([]byte("\x00" + name + "\x00"))
+		h.Write(contents)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// BuildCached is like Build, but reuses a previous build of the same source
+// with the same flags and compiler, unless rebuild is set.
+func (lang LanguageProfile) BuildCached(src string, flags []string, rebuild bool) (program []string, cached bool, out string, err error) {
+	if len(lang.Compile) == 0 {
+		program, out, err = lang.Build(src, "", flags)
+		return
+	}
+	if src, err = filepath.Abs(src); err != nil {
+		return
+	}
+	cacheDir, err := BuildCacheDir()
+	if err != nil {
+		return
+	}
+	key, err := lang.buildKey(src, flags)
+	if err != nil {
+		return
+	}
+	name := strings.TrimSuffix(filepath.Base(src), filepath.Ext(src))
+	dir := filepath.Join(cacheDir, key)
+	program = expandCommand(lang.Run, src, filepath.Join(dir, name), nil)
+	if !rebuild && FileExists(dir) {
+		return program, true, "", nil
+	}
+
+	// build elsewhere and move it into place, so that the cache never holds
+	// a partial build
+	os.MkdirAll(cacheDir, os.ModePerm)
+	tmp, err := ioutil.TempDir(cacheDir, key+".tmp")
+	if err != nil {
+		return
+	}
+	if _, out, err = lang.Build(src, filepath.Join(tmp, name), flags); err != nil {
+		os.RemoveAll(tmp)
+		return nil, false, out, err
+	}
+	os.RemoveAll(dir)
+	if err = os.Rename(tmp, dir); err != nil {
+		os.RemoveAll(tmp)
+		if FileExists(dir) {
+			// another gocf process built it at the same time
+			err = nil
+		}
+	}
+	return
+}
+
+func hasExtension(name string, extensions []string) bool {
+	for _, ext := range extensions {
+		if filepath.Ext(name) == ext {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,328 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Built-in checkers, selected by name in the session Checker property.
+// They are ports of the standard testlib checkers.
+// @see https://github.com/MikeMirzayanov/testlib/tree/master/checkers
+
+type builtinChecker func(outputFile, answerFile string) error
+
+var BuiltinCheckers = map[string]builtinChecker{
+	"*":               lcmp,
+	"lcmp":            lcmp,
+	"wcmp":            wcmp,
+	"ncmp":            ncmp,
+	"rcmp":            rcmp(1.5e-6),
+	"rcmp4":           rcmp(1e-4),
+	"rcmp6":           rcmp(1e-6),
+	"rcmp9":           rcmp(1e-9),
+	"yesno":           yesno,
+	"nyesno":          nyesno,
+	"exact":           exact,
+	"unordered-lines": unorderedLines,
+}
+
+func IsBuiltinChecker(name string) bool {
+	_, ok := BuiltinCheckers[name]
+	return ok
+}
+
+// presentationError is returned by checkers when the output is malformed,
+// rather than wrong.
+type presentationError struct {
+	msg string
+}
+
+func (e presentationError) Error() string {
+	return e.msg
+}
+
+// checkerFailure is returned by checkers when the answer is malformed, which
+// testlib checkers report as a failure of their own.
+type checkerFailure struct {
+	msg string
+}
+
+func (e checkerFailure) Error() string {
+	return e.msg
+}
+
+func englishEnding(n int) string {
+	if n%100 >= 11 && n%100 <= 13 {
+		return "th"
+	}
+	switch n % 10 {
+	case 1:
+		return "st"
+	case 2:
+		return "nd"
+	case 3:
+		return "rd"
+	default:
+		return "th"
+	}
+}
+
+// readTokens returns the whitespace separated tokens of a file.
+func readTokens(filename string) ([]string, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1<<30)
+	scanner.Split(bufio.ScanWords)
+	var tokens []string
+	for scanner.Scan() {
+		tokens = append(tokens, scanner.Text())
+	}
+	return tokens, scanner.Err()
+}
+
+// compareTokens compares the tokens of the output and the answer one by one
+// with cmp, which describes the first difference found. What is a token is
+// told by name, for the messages.
+func compareTokens(outputFile, answerFile, name string, cmp func(n int, out, ans string) error) error {
+	out, err := readTokens(outputFile)
+	if err != nil {
+		return err
+	}
+	ans, err := readTokens(answerFile)
+	if err != nil {
+		return checkerFailure{err.Error()}
+	}
+	for i := 0; i < len(ans); i++ {
+		if i == len(out) {
+			return fmt.Errorf("answer contains longer sequence [length = %d], but output contains %d %ss", len(ans), len(out), name)
+		}
+		if err := cmp(i+1, out[i], ans[i]); err != nil {
+			return err
+		}
+	}
+	if len(out) > len(ans) {
+		return fmt.Errorf("output contains longer sequence [length = %d], but answer contains %d %ss", len(out), len(ans), name)
+	}
+	return nil
+}
+
+// wcmp compares sequences of tokens.
+func wcmp(outputFile, answerFile string) error {
+	return compareTokens(outputFile, answerFile, "token", func(n int, out, ans string) error {
+		if out != ans {
+			return fmt.Errorf("%d%s words differ - expected: '%s', found: '%s'", n, englishEnding(n), ans, out)
+		}
+		return nil
+	})
+}
+
+// ncmp compares sequences of signed 64-bit integers.
+func ncmp(outputFile, answerFile string) error {
+	return compareTokens(outputFile, answerFile, "number", func(n int, out, ans string) error {
+		a, err := strconv.ParseInt(ans, 10, 64)
+		if err != nil {
+			return checkerFailure{fmt.Sprintf("expected an integer as the %d%s number of the answer, found: '%s'", n, englishEnding(n), ans)}
+		}
+		o, err := strconv.ParseInt(out, 10, 64)
+		if err != nil {
+			return presentationError{fmt.Sprintf("expected an integer as the %d%s number, found: '%s'", n, englishEnding(n), out)}
+		}
+		if o != a {
+			return fmt.Errorf("%d%s numbers differ - expected: '%s', found: '%s'", n, englishEnding(n), ans, out)
+		}
+		return nil
+	})
+}
+
+// rcmp compares sequences of floating point numbers, with a maximal absolute
+// or relative error of eps.
+func rcmp(eps float64) builtinChecker {
+	return func(outputFile, answerFile string) error {
+		return compareTokens(outputFile, answerFile, "number", func(n int, out, ans string) error {
+			a, err := strconv.ParseFloat(ans, 64)
+			if err != nil {
+				return checkerFailure{fmt.Sprintf("expected a real number as the %d%s number of the answer, found: '%s'", n, englishEnding(n), ans)}
+			}
+			o, err := strconv.ParseFloat(out, 64)
+			if err != nil || math.IsNaN(o) || math.IsInf(o, 0) {
+				return presentationError{fmt.Sprintf("expected a real number as the %d%s number, found: '%s'", n, englishEnding(n), out)}
+			}
+			if !doubleCompare(a, o, eps) {
+				return fmt.Errorf("%d%s numbers differ - expected: '%.10f', found: '%.10f', error = '%.10f'",
+					n, englishEnding(n), a, o, doubleDelta(a, o))
+			}
+			return nil
+		})
+	}
+}
+
+func doubleCompare(expected, result, eps float64) bool {
+	return math.Abs(result-expected) <= eps+1e-15 || math.Abs(result-expected) <= eps*math.Abs(expected)+1e-15
+}
+
+// doubleDelta returns the smallest of the absolute and relative errors.
+func doubleDelta(expected, result float64) float64 {
+	absolute := math.Abs(result - expected)
+	if math.Abs(expected) > 1e-9 {
+		return math.Min(absolute, absolute/math.Abs(expected))
+	}
+	return absolute
+}
+
+func normalizeYesNo(n int, token string) (string, error) {
+	upper := strings.ToUpper(token)
+	if upper != "YES" && upper != "NO" {
+		return "", presentationError{fmt.Sprintf("YES or NO expected as the %d%s token, but '%s' found", n, englishEnding(n), token)}
+	}
+	return upper, nil
+}
+
+// yesno compares a single YES or NO, ignoring case.
+func yesno(outputFile, answerFile string) error {
+	out, err := readTokens(outputFile)
+	if err != nil {
+		return err
+	}
+	ans, err := readTokens(answerFile)
+	if err != nil {
+		return checkerFailure{err.Error()}
+	}
+	if len(ans) != 1 {
+		return checkerFailure{fmt.Sprintf("answer must contain a single YES or NO, but contains %d tokens", len(ans))}
+	}
+	if len(out) != 1 {
+		return presentationError{fmt.Sprintf("a single YES or NO expected, but %d tokens found", len(out))}
+	}
+	o, err := normalizeYesNo(1, out[0])
+	if err != nil {
+		return err
+	}
+	a, err := normalizeYesNo(1, ans[0])
+	if err != nil {
+		return checkerFailure{"in the answer: " + err.Error()}
+	}
+	if o != a {
+		return fmt.Errorf("expected %s, found %s", a, o)
+	}
+	return nil
+}
+
+// nyesno compares sequences of YES and NO, ignoring case.
+func nyesno(outputFile, answerFile string) error {
+	return compareTokens(outputFile, answerFile, "token", func(n int, out, ans string) error {
+		a, err := normalizeYesNo(n, ans)
+		if err != nil {
+			return checkerFailure{"in the answer: " + err.Error()}
+		}
+		o, err := normalizeYesNo(n, out)
+		if err != nil {
+			return err
+		}
+		if o != a {
+			return fmt.Errorf("expected %s, found %s [%d%s token]", a, o, n, englishEnding(n))
+		}
+		return nil
+	})
+}
+
+// exact compares the files byte by byte.
+func exact(outputFile, answerFile string) error {
+	out, err := ioutil.ReadFile(outputFile)
+	if err != nil {
+		return err
+	}
+	ans, err := ioutil.ReadFile(answerFile)
+	if err != nil {
+		return checkerFailure{err.Error()}
+	}
+	n := 0
+	for n < len(out) && n < len(ans) && out[n] == ans[n] {
+		n++
+	}
+	if n == len(out) && n == len(ans) {
+		return nil
+	}
+	line := bytes.Count(ans[:n], []byte("\n")) + 1
+	column := n - bytes.LastIndexByte(ans[:n], '\n')
+	switch {
+	case n == len(out):
+		return fmt.Errorf("output ends at byte %d (line %d, column %d), but the answer is %d bytes long", n, line, column, len(ans))
+	case n == len(ans):
+		return fmt.Errorf("output is longer than the answer, which ends at byte %d (line %d, column %d)", n, line, column)
+	default:
+		return fmt.Errorf("files differ at byte %d (line %d, column %d) - expected: %q, found: %q", n, line, column, ans[n], out[n])
+	}
+}
+
+func readLines(filename string) ([]string, error) {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var lines []string
+	for _, line := range strings.Split(string(b), "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		if len(line) > 0 {
+			lines = append(lines, line)
+		}
+	}
+	return lines, nil
+}
+
+// unorderedLines compares the non-empty lines of the files in any order.
+// Trailing whitespace is ignored.
+func unorderedLines(outputFile, answerFile string) error {
+	out, err := readLines(outputFile)
+	if err != nil {
+		return err
+	}
+	ans, err := readLines(answerFile)
+	if err != nil {
+		return checkerFailure{err.Error()}
+	}
+	if len(out) != len(ans) {
+		return fmt.Errorf("expected %d lines, found %d", len(ans), len(out))
+	}
+	sort.Strings(out)
+	sort.Strings(ans)
+	for i, j := 0, 0; i < len(ans) || j < len(out); {
+		switch {
+		case j == len(out) || (i < len(ans) && ans[i] < out[j]):
+			return fmt.Errorf("expected line not found in the output: '%s'", ans[i])
+		case i == len(ans) || out[j] < ans[i]:
+			return fmt.Errorf("unexpected line found in the output: '%s'", out[j])
+		}
+		i++
+		j++
+	}
+	return nil
+}
+
+// runBuiltinChecker maps the result of a built-in checker to a verdict.
+func runBuiltinChecker(check builtinChecker, outputFile, answerFile string) (Verdict, CheckOutcome) {
+	err := check(outputFile, answerFile)
+	var pe presentationError
+	var cf checkerFailure
+	switch {
+	case err == nil:
+		return OK, CheckOutcome{}
+	case errors.As(err, &pe):
+		return PE, CheckOutcome{Message: err.Error()}
+	case errors.As(err, &cf):
+		return FAIL, CheckOutcome{Message: err.Error()}
+	default:
+		return WA, CheckOutcome{Message: err.Error()}
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBuiltinCheckers(t *testing.T) {
+	tests := []struct {
+		checker string
+		output  string
+		answer  string
+		verdict Verdict
+		message string
+	}{
+		{"lcmp", "1 2\n3\n", "1  2\n3", OK, ""},
+		{"lcmp", "1 2\n3\n", "1 2 3\n", WA, "line 0 differ - expected: 1 2 3, found: 1 2"},
+		{"wcmp", "a b\nc", "a b c\n", OK, ""},
+		{"wcmp", "a b d", "a b c", WA, "3rd words differ - expected: 'c', found: 'd'"},
+		{"wcmp", "a b", "a b c", WA, "answer contains longer sequence [length = 3], but output contains 2 tokens"},
+		{"ncmp", "1 -2 3", "1\n-2\n3\n", OK, ""},
+		{"ncmp", "1 2 4", "1 2 3", WA, "3rd numbers differ - expected: '3', found: '4'"},
+		{"ncmp", "1 2 3 4", "1 2 3", WA, "output contains longer sequence [length = 4], but answer contains 3 numbers"},
+		{"ncmp", "1 x", "1 2", PE, "expected an integer as the 2nd number, found: 'x'"},
+		{"ncmp", "1 2", "1 x", FAIL, "expected an integer as the 2nd number of the answer, found: 'x'"},
+		{"rcmp6", "0.3333333", "0.33333333", OK, ""},
+		{"rcmp6", "1000000.5", "1000000", OK, ""},
+		{"rcmp6", "0.5", "0.25", WA, "1st numbers differ - expected: '0.2500000000', found: '0.5000000000', error = '0.2500000000'"},
+		{"rcmp6", "nan", "0.5", PE, "expected a real number as the 1st number, found: 'nan'"},
+		{"rcmp6", "0.5", "half", FAIL, "expected a real number as the 1st number of the answer, found: 'half'"},
+		{"yesno", "yes", "YES", OK, ""},
+		{"yesno", "NO", "YES", WA, "expected YES, found NO"},
+		{"yesno", "YES YES", "YES", PE, "a single YES or NO expected, but 2 tokens found"},
+		{"yesno", "maybe", "YES", PE, "YES or NO expected as the 1st token, but 'maybe' found"},
+		{"yesno", "YES", "YES NO", FAIL, "answer must contain a single YES or NO, but contains 2 tokens"},
+		{"yesno", "YES", "maybe", FAIL, "in the answer: YES or NO expected as the 1st token, but 'maybe' found"},
+		{"nyesno", "yes No", "YES NO", OK, ""},
+		{"nyesno", "YES YES", "YES NO", WA, "expected NO, found YES [2nd token]"},
+		{"nyesno", "YES maybe", "YES NO", PE, "YES or NO expected as the 2nd token, but 'maybe' found"},
+		{"nyesno", "YES NO", "YES maybe", FAIL, "in the answer: YES or NO expected as the 2nd token, but 'maybe' found"},
+		{"exact", "a\nb\n", "a\nb\n", OK, ""},
+		{"exact", "a\nc\n", "a\nb\n", WA, "files differ at byte 2 (line 2, column 1) - expected: 'b', found: 'c'"},
+		{"exact", "a\n", "a\nb\n", WA, "output ends at byte 2 (line 2, column 1), but the answer is 4 bytes long"},
+		{"unordered-lines", "b\na \n\n", "a\nb\n", OK, ""},
+		{"unordered-lines", "a\nc\n", "a\nb\n", WA, "expected line not found in the output: 'b'"},
+		{"unordered-lines", "a\n", "a\nb\n", WA, "expected 2 lines, found 1"},
+	}
+	dir := t.TempDir()
+	outputFile := filepath.Join(dir, "test.out")
+	answerFile := filepath.Join(dir, "test.ans")
+	for _, test := range tests {
+		ioutil.WriteFile(outputFile, []byte(test.output), os.ModePerm)
+		ioutil.WriteFile(answerFile, []byte(test.answer), os.ModePerm)
+		verdict, outcome := runBuiltinChecker(BuiltinCheckers[test.checker], outputFile, answerFile)
+		if verdict != test.verdict || outcome.Message != test.message {
+			t.Errorf("%s(%q, %q) = %v, %q; want %v, %q",
+				test.checker, test.output, test.answer, verdict, outcome.Message, test.verdict, test.message)
+		}
+	}
+}
+
+func TestBuiltinCheckerMissingAnswer(t *testing.T) {
+	dir := t.TempDir()
+	outputFile := filepath.Join(dir, "test.out")
+	ioutil.WriteFile(outputFile, []byte("1\n"), os.ModePerm)
+	for name, check := range BuiltinCheckers {
+		if name == "*" || name == "lcmp" {
+			continue
+		}
+		if verdict, _ := runBuiltinChecker(check, outputFile, filepath.Join(dir, "test.ans")); verdict != FAIL {
+			t.Errorf("%s without an answer = %v; want FAIL", name, verdict)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+// how many bytes of the standard error of a solution are kept (the last ones)
+const StderrLimit = 64 * 1024
+
+// how many stack frames of a Go panic are shown
+const PanicFrames = 5
+
+// tailBuffer keeps the last limit bytes of what is written to it. Crash
+// messages are written last, after any debug output.
+type tailBuffer struct {
+	mu        sync.Mutex
+	buf       []byte
+	limit     int
+	truncated bool
+}
+
+func (tb *tailBuffer) Write(p []byte) (int, error) {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	tb.buf = append(tb.buf, p...)
+	if len(tb.buf) > 2*tb.limit {
+		tb.buf = append(tb.buf[:0], tb.buf[len(tb.buf)-tb.limit:]...)
+		tb.truncated = true
+	}
+	return len(p), nil
+}
+
+func (tb *tailBuffer) String() string {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	if len(tb.buf) > tb.limit {
+		return "... (truncated)\n" + string(tb.buf[len(tb.buf)-tb.limit:])
+	}
+	if tb.truncated {
+		return "... (truncated)\n" + string(tb.buf)
+	}
+	return string(tb.buf)
+}
+
+var signalNames = map[syscall.Signal]string{
+	syscall.SIGABRT: "SIGABRT",
+	syscall.SIGBUS:  "SIGBUS",
+	syscall.SIGFPE:  "SIGFPE",
+	syscall.SIGILL:  "SIGILL",
+	syscall.SIGKILL: "SIGKILL",
+	syscall.SIGPIPE: "SIGPIPE",
+	syscall.SIGSEGV: "SIGSEGV",
+	syscall.SIGSYS:  "SIGSYS",
+	syscall.SIGTERM: "SIGTERM",
+	syscall.SIGTRAP: "SIGTRAP",
+	syscall.SIGXCPU: "SIGXCPU",
+	syscall.SIGXFSZ: "SIGXFSZ",
+}
+
+func signalName(sig syscall.Signal) string {
+	if name, ok := signalNames[sig]; ok {
+		return name
+	}
+	return sig.String()
+}
+
+// messages which tell a stack overflow or an out of memory condition apart,
+// as printed by the runtimes of the supported languages
+var (
+	stackOverflowMessages = []string{
+		"goroutine stack exceeds",
+		"fatal error: stack overflow",
+		"java.lang.StackOverflowError",
+		"RecursionError: maximum recursion depth exceeded",
+		"has overflowed its stack",
+	}
+	outOfMemoryMessages = []string{
+		"fatal error: runtime: out of memory",
+		"std::bad_alloc",
+		"java.lang.OutOfMemoryError",
+		"MemoryError",
+		"memory allocation of",
+	}
+)
+
+func containsAny(s string, substrs []string) bool {
+	for _, sub := range substrs {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
+
+// goCrashLine tells whether a line starts the crash report of the Go
+// runtime: a panic, a fatal error or a signal such as "SIGSEGV: ...".
+func goCrashLine(line string) bool {
+	if strings.HasPrefix(line, "panic: ") || strings.HasPrefix(line, "fatal error: ") {
+		return true
+	}
+	i := strings.Index(line, ": ")
+	return strings.HasPrefix(line, "SIG") && i > 3 && strings.ToUpper(line[:i]) == line[:i]
+}
+
+// goPanic returns the message of a Go panic (or fatal error) found in the
+// standard error, followed by the top PanicFrames frames of the panicking
+// goroutine.
+func goPanic(stderr string) string {
+	lines := strings.Split(stderr, "\n")
+	start := -1
+	for i, line := range lines {
+		if goCrashLine(line) {
+			start = i
+			break
+		}
+	}
+	if start < 0 {
+		return ""
+	}
+	end := start
+	for end < len(lines) && !strings.HasPrefix(lines[end], "goroutine ") {
+		end++
+	}
+	// each frame takes two lines: the function and its file
+	end += 1 + 2*PanicFrames
+	if end > len(lines) {
+		end = len(lines)
+	}
+	return strings.TrimSpace(strings.Join(lines[start:end], "\n"))
+}
+
+// crashCause tells why a run ended with a runtime error: a stack overflow,
+// running out of memory, a Go panic, a signal or a non-zero exit code. For Go
+// crashes, the message and the top of the stack trace are also returned.
+func crashCause(stats RunStats) (cause, trace string) {
+	trace = goPanic(stats.Stderr)
+	switch {
+	case containsAny(stats.Stderr, stackOverflowMessages):
+		cause = "stack overflow"
+	case containsAny(stats.Stderr, outOfMemoryMessages):
+		cause = "out of memory"
+	case trace != "":
+		cause = strings.SplitN(trace, "\n", 2)[0]
+	case stats.Signal != "":
+		cause = "killed by " + stats.Signal
+	case stats.ExitCode > 0:
+		cause = "exit code " + strconv.Itoa(stats.ExitCode)
+	}
+	return
+}
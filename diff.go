@@ -0,0 +1,229 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+)
+
+const (
+	DiffContext  = 3   // lines shown before the first mismatch
+	DiffWindow   = 40  // lines compared from the first mismatch on
+	DiffMaxWidth = 100 // characters shown per line
+)
+
+// mismatch is the first place where the output and the answer differ, as
+// lcmp would see it. Lines and tokens are 0-based, column is the byte offset
+// of the token within the line.
+type mismatch struct {
+	line, token, column int
+	expected, found     string
+}
+
+func readDiffLines(filename string) []string {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil
+	}
+	lines := strings.Split(string(b), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// tokenOffsets returns the space separated tokens of a line and their offsets.
+func tokenOffsets(line string) ([]string, []int) {
+	var tokens []string
+	var offsets []int
+	for i := 0; i < len(line); {
+		if line[i] == ' ' {
+			i++
+			continue
+		}
+		j := i
+		for j < len(line) && line[j] != ' ' {
+			j++
+		}
+		tokens = append(tokens, line[i:j])
+		offsets = append(offsets, i)
+		i = j
+	}
+	return tokens, offsets
+}
+
+func lineAt(lines []string, i int) string {
+	if i < len(lines) {
+		return lines[i]
+	}
+	return ""
+}
+
+func findMismatch(out, ans []string) (mismatch, bool) {
+	for i := 0; i < len(out) || i < len(ans); i++ {
+		o := lineAt(out, i)
+		a := lineAt(ans, i)
+		if compareWords(a, o) {
+			continue
+		}
+		ot, oo := tokenOffsets(o)
+		at, _ := tokenOffsets(a)
+		m := mismatch{line: i}
+		for m.token < len(ot) && m.token < len(at) && ot[m.token] == at[m.token] {
+			m.token++
+		}
+		if m.token < len(at) {
+			m.expected = at[m.token]
+		}
+		if m.token < len(ot) {
+			m.found = ot[m.token]
+			m.column = oo[m.token]
+		} else {
+			m.column = len(o)
+		}
+		return m, true
+	}
+	return mismatch{}, false
+}
+
+// visible makes whitespace visible and cuts the line to DiffMaxWidth
+// characters, keeping the given column in sight.
+func visible(line string, column int) string {
+	r := strings.NewReplacer(" ", "·", "\t", "→", "\r", "␍")
+	prefix, suffix := "", "$"
+	if len(line) > DiffMaxWidth {
+		start := column - DiffMaxWidth/2
+		if start < 0 {
+			start = 0
+		}
+		if start+DiffMaxWidth > len(line) {
+			start = len(line) - DiffMaxWidth
+		}
+		if start > 0 {
+			prefix = "…"
+		}
+		if start+DiffMaxWidth < len(line) {
+			suffix = "…"
+		}
+		line = line[start : start+DiffMaxWidth]
+	}
+	return prefix + r.Replace(line) + suffix
+}
+
+// diffOp is a line of a diff: op is ' ' for lines common to both sides,
+// '-' for lines only in the answer and '+' for lines only in the output.
+// line is the 0-based number of the line in its side (the answer for
+// common lines).
+type diffOp struct {
+	op   byte
+	line int
+	text string
+}
+
+// diffLines computes a line diff of a and b, from their longest common
+// subsequence.
+func diffLines(a, b []string) []diffOp {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	var ret []diffOp
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			ret = append(ret, diffOp{' ', i, a[i]})
+			i++
+			j++
+		case j == len(b) || (i < len(a) && lcs[i+1][j] >= lcs[i][j+1]):
+			ret = append(ret, diffOp{'-', i, a[i]})
+			i++
+		default:
+			ret = append(ret, diffOp{'+', j, b[j]})
+			j++
+		}
+	}
+	return ret
+}
+
+// changed tells whether there's a changed line within DiffContext lines of
+// ops[i].
+func changed(ops []diffOp, i int) bool {
+	for j := i - DiffContext; j <= i+DiffContext; j++ {
+		if j >= 0 && j < len(ops) && ops[j].op != ' ' {
+			return true
+		}
+	}
+	return false
+}
+
+func window(lines []string, from, to int) []string {
+	if to > len(lines) {
+		to = len(lines)
+	}
+	if from > to {
+		from = to
+	}
+	return lines[from:to]
+}
+
+// Diff describes where the output differs from the answer: the first
+// mismatching line and token, as lcmp finds them, followed by a unified diff
+// of the lines around it, with whitespace made visible. It returns an empty
+// string if lcmp finds no difference.
+func Diff(outputFile, answerFile string) string {
+	out := readDiffLines(outputFile)
+	ans := readDiffLines(answerFile)
+	m, found := findMismatch(out, ans)
+	if !found {
+		return ""
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "First mismatch at line %d, token %d: ", m.line+1, m.token+1)
+	switch {
+	case m.expected == "":
+		fmt.Fprintf(&sb, "expected end of line, found %q\n", m.found)
+	case m.found == "":
+		fmt.Fprintf(&sb, "expected %q, found end of line\n", m.expected)
+	default:
+		fmt.Fprintf(&sb, "expected %q, found %q\n", m.expected, m.found)
+	}
+
+	from := m.line - DiffContext
+	if from < 0 {
+		from = 0
+	}
+	a := window(ans, from, m.line+DiffWindow)
+	b := window(out, from, m.line+DiffWindow)
+	fmt.Fprintln(&sb, "(- expected, + found)")
+	ops := diffLines(a, b)
+	skipped := false
+	for i, op := range ops {
+		if !changed(ops, i) {
+			if !skipped {
+				sb.WriteString("  ...\n")
+			}
+			skipped = true
+			continue
+		}
+		skipped = false
+		fmt.Fprintf(&sb, "%c %5d| %s\n", op.op, from+op.line+1, visible(op.text, m.column))
+	}
+	if m.line+DiffWindow < len(ans) || m.line+DiffWindow < len(out) {
+		fmt.Fprintf(&sb, "... (only lines up to %d are compared, %d expected and %d found in total)\n",
+			m.line+DiffWindow, len(ans), len(out))
+	}
+	return sb.String()
+}
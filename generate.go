@@ -0,0 +1,155 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// GenScript is the generator script of a session, in the session directory.
+// Each line runs a generator found in the session directory, with the given
+// arguments, and makes a test of its output, e.g.:
+//
+//	gen 10 100000 --seed=3 > $
+const GenScript = "gen.script"
+
+// genPath returns the file recording the generator command of test #id.
+func genPath(config GocfConfig, id int) string {
+	return config.SessionDir + "/" + strconv.Itoa(id) + ".gen"
+}
+
+// genCommand is a line of a generator script.
+type genCommand struct {
+	line int
+	args []string // the generator name, followed by its arguments
+}
+
+func (c genCommand) String() string {
+	return strings.Join(c.args, " ")
+}
+
+func parseGenScript(script []byte) ([]genCommand, error) {
+	var commands []genCommand
+	for i, line := range strings.Split(string(script), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		parts := strings.Split(line, ">")
+		if len(parts) != 2 || strings.TrimSpace(parts[1]) != "$" {
+			return nil, fmt.Errorf("line %d: expected \"<generator> [args...] > $\", found: %s", i+1, line)
+		}
+		args := strings.Fields(parts[0])
+		if len(args) == 0 {
+			return nil, fmt.Errorf("line %d: missing generator", i+1)
+		}
+		commands = append(commands, genCommand{i + 1, args})
+	}
+	return commands, nil
+}
+
+// findGenerator returns the path of a generator given by name: either an
+// executable with that name, or a source file with that base name.
+func findGenerator(config GocfConfig, name string) (string, error) {
+	path := sessionFilePath(config, name)
+	if FileExists(path) {
+		return path, nil
+	}
+	matches, _ := filepath.Glob(path + ".*")
+	for _, match := range matches {
+		if _, err := LanguageByFile(match); err == nil {
+			return match, nil
+		}
+	}
+	return "", errors.New("Generator not found: " + name)
+}
+
+// recordedTests maps the generator commands recorded for the tests to their ids.
+func recordedTests(config GocfConfig) map[string]int {
+	ids := make(map[string]int)
+	for id := 1; FileExists(inPath(config, id)); id++ {
+		if b, err := ioutil.ReadFile(genPath(config, id)); err == nil {
+			ids[strings.TrimSpace(string(b))] = id
+		}
+	}
+	return ids
+}
+
+// GenerateTests runs the generator script of the session. Tests made by a
+// line run before are generated again in place, so that they can be rebuilt
+// from the script (e.g. after a restore), and the others are added as new
+// tests. The command of each test is recorded next to it.
+func GenerateTests(config GocfConfig) {
+	session := LoadCurrentSession(config)
+	script, err := ioutil.ReadFile(config.SessionDir + "/" + GenScript)
+	if err != nil {
+		fmt.Println("No generator script found, expected at", config.SessionDir+"/"+GenScript)
+		os.Exit(1)
+	}
+	commands, err := parseGenScript(script)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	var validator []string
+	if session.Validator != "" {
+		validator = RequireValidator(config, session)
+	}
+
+	generators := make(map[string][]string)
+	recorded := recordedTests(config)
+	added, changed := 0, 0
+	for _, c := range commands {
+		name := c.args[0]
+		if _, ok := generators[name]; !ok {
+			path, err := findGenerator(config, name)
+			if err == nil {
+				generators[name], err = sessionProgram(config, "Generator", path)
+			}
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+		}
+		program := generators[name]
+		args := append(append([]string{}, program[1:]...), c.args[1:]...)
+		cmd := exec.Command(program[0], args...)
+		cmd.Dir = config.SessionDir
+		stderr := &truncBuffer{limit: PrintLimit}
+		cmd.Stderr = stderr
+		input, err := cmd.Output()
+		if err != nil {
+			fmt.Printf("Line %d: generator failed: %v\n%s\n", c.line, err, stderr.String())
+			os.Exit(1)
+		}
+		if validator != nil {
+			if err := validateInput(validator, input); err != nil {
+				fmt.Printf("Line %d: invalid input: %v\n", c.line, err)
+				os.Exit(1)
+			}
+		}
+
+		if id, ok := recorded[c.String()]; ok {
+			if old, _ := ioutil.ReadFile(inPath(config, id)); !bytes.Equal(old, input) {
+				fmt.Printf("Test #%d changed (is the generator deterministic?): %s\n", id, c)
+				ioutil.WriteFile(inPath(config, id), input, os.ModePerm)
+				// the answer was for the old input
+				os.Remove(ansPath(config, id))
+				changed++
+			}
+			continue
+		}
+		id := AddTest(config, input, nil)
+		ioutil.WriteFile(genPath(config, id), []byte(c.String()+"\n"), os.ModePerm)
+		recorded[c.String()] = id
+		fmt.Printf("Added test #%d: %s\n", id, c)
+		added++
+	}
+	fmt.Println(added, "tests added,", changed, "tests changed,", len(commands)-added-changed, "tests unchanged")
+}
@@ -1,10 +1,12 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/url"
 	"os"
+	"path/filepath"
 	"strconv"
 )
 
@@ -23,14 +25,46 @@ func CreateSession(config GocfConfig) {
 	output := ReadDefault("Enter output file name", DefaultOutput)
 	tl := ReadDefault("Enter time limit", strconv.Itoa(DefaultTimeLimit))
 	ml := ReadDefault("Enter memory limit", strconv.Itoa(DefaultMemLimit))
+	il := ReadDefault("Enter idle limit (0 for 3 times the time limit)", "0")
 	checker := ReadDefault("Enter task checker", DefaultChecker)
+	language := ReadDefault("Enter language", DefaultLanguage)
+	for {
+		_, err := SessionLanguage(config, GocfSession{Language: language})
+		if err == nil {
+			break
+		}
+		fmt.Println(err)
+		language = ReadDefault("Enter language", DefaultLanguage)
+	}
 
 	timeLimit, _ := strconv.Atoi(tl)
 	memLimit, _ := strconv.Atoi(ml)
-	session = GocfSession{contest, task, input, output, timeLimit, memLimit, checker}
+	idleLimit, _ := strconv.Atoi(il)
+	if _, err := LanguageByFile(checker); err == nil && FileExists(checker) {
+		// keep the checker source with the session so that it gets archived
+		CopyFile(checker, config.SessionDir+"/"+filepath.Base(checker))
+		checker = filepath.Base(checker)
+	}
+	session = GocfSession{
+		Contest:     contest,
+		Task:        task,
+		Input:       input,
+		Output:      output,
+		TimeLimit:   timeLimit,
+		MemLimit:    memLimit,
+		IdleLimit:   idleLimit,
+		Checker:     checker,
+		OutputLimit: DefaultOutputLimit,
+		Language:    language,
+	}
 	session.Save(config)
 
-	ioutil.WriteFile(config.WorkFile, []byte(GoTemplate(session)), os.ModePerm)
+	lang, err := SessionLanguage(config, session)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	ioutil.WriteFile(config.WorkFile, []byte(lang.Template(session)), os.ModePerm)
 	fmt.Println("done")
 }
 
@@ -51,7 +85,11 @@ func ImportSession(config GocfConfig, s string) {
 			panic(err)
 		}
 		session.Save(config)
-		ioutil.WriteFile(config.WorkFile, []byte(GoTemplate(session)), os.ModePerm)
+		lang, err := SessionLanguage(config, session)
+		if err != nil {
+			panic(err)
+		}
+		ioutil.WriteFile(config.WorkFile, []byte(lang.Template(session)), os.ModePerm)
 
 		nrOfTests := len(inputs)
 		for id := 1; id <= nrOfTests; id++ {
@@ -83,7 +121,7 @@ func ListSession(config GocfConfig) {
 }
 
 func PrintUsage() {
-	fmt.Println(`
+	fmt.Print(`
 -----------------------------
    ____        ____ _____
   / ___| ___  / ___|  ___|
@@ -97,8 +135,33 @@ Usage: gocf <cmd> [args...]
 where <cmd> is one of:
   create                   - create a new session
   import <url>             - create a new session from a supported url (e.g. Codeforces, Timus)
-  test                     - compile and run work file againts current tests
-  add                      - add a new test to current session
+  test [ids...] [options]  - compile and run work file againts current tests
+                             (or only the given ids and ranges, e.g. 3 5-7)
+      -j N                   run up to N tests at the same time
+      --fail-fast            stop at the first test not passing
+      --rebuild              build the work file even if it hasn't changed
+      --repeat N             run each test N times and summarize the CPU times
+      --format F             print the results as text (default), json or junit;
+                             other messages go to the standard error
+  watch [ids...] [options] - run the tests like test, again every time the work file
+                             or the session files change, printing only a summary
+  stress <gen> <brute>     - compare work file with a brute-force solution on tests
+         [options]           made by a generator (given a seed), and add the first
+                             counterexample as a new test
+      -n, --iterations N     stop after N tests (default 1000)
+      --time S               stop after S seconds
+  bench <gen> --max N      - run work file on inputs of growing size made by a generator
+         [options]           (given n), fit its CPU time and memory to complexity classes
+                             (O(n), O(n log n), O(n²)...) and extrapolate them to n = N
+      --sizes N,N,...        the sizes to run (default: doubling from N/64 to N/2)
+      --repeat R             keep the fastest of R runs at each size (default 3)
+  add [--no-validate]      - add a new test to current session, checking its input
+                             with the session validator (if any) unless told not to
+  gen                      - add the tests made by the generator script of the session
+                             (` + GenScript + `), and make again those already added
+  validate                 - check the input of every test with the session validator
+  answers --with <ref>     - write the answer of every test without one, running a
+                             reference solution on it
   rm <id>                  - remove the test #id from current session
   archive                  - archive current session
   restore <contest> <task> - restore an archived session 
@@ -119,9 +182,28 @@ func main() {
 		PrintUsage()
 		os.Exit(0)
 	}
+	if os.Args[1] == SandboxCommand {
+		// gocf running a solution inside the sandbox
+		RunSandbox(os.Args[2:])
+	}
 
-	config := LoadConfig()
 	cmd := os.Args[1]
+	var testOpts TestOptions
+	if cmd == "test" {
+		var err error
+		if testOpts, err = ParseTestOptions(os.Args[2:]); err != nil {
+			fmt.Println(err)
+			PrintUsage()
+			os.Exit(1)
+		}
+		if testOpts.Format != FormatText {
+			// keep the standard output for the report
+			testOpts.Report = os.Stdout
+			os.Stdout = os.Stderr
+		}
+	}
+
+	config := LoadConfig()
 	switch cmd {
 	case "create":
 		CheckArgCount(0)
@@ -130,10 +212,55 @@ func main() {
 		CheckArgCount(1)
 		ImportSession(config, os.Args[2])
 	case "test":
-		TestAll(config)
+		TestAll(config, testOpts)
+	case "watch":
+		opts, err := ParseTestOptions(os.Args[2:])
+		if err == nil && opts.Format != FormatText {
+			err = errors.New("watch only supports the text format")
+		}
+		if err != nil {
+			fmt.Println(err)
+			PrintUsage()
+			os.Exit(1)
+		}
+		Watch(config, opts)
+	case "stress":
+		gen, brute, opts, err := ParseStressOptions(os.Args[2:])
+		if err != nil {
+			fmt.Println(err)
+			PrintUsage()
+			os.Exit(1)
+		}
+		Stress(config, gen, brute, opts)
+	case "bench":
+		gen, opts, err := ParseBenchOptions(os.Args[2:])
+		if err != nil {
+			fmt.Println(err)
+			PrintUsage()
+			os.Exit(1)
+		}
+		Bench(config, gen, opts)
 	case "add":
+		validate := true
+		if len(os.Args) == 3 && os.Args[2] == "--no-validate" {
+			validate = false
+		} else {
+			CheckArgCount(0)
+		}
+		AddTestFromUser(config, validate)
+	case "gen":
 		CheckArgCount(0)
-		AddTestFromUser(config)
+		GenerateTests(config)
+	case "validate":
+		CheckArgCount(0)
+		ValidateTests(config)
+	case "answers":
+		CheckArgCount(2)
+		if os.Args[2] != "--with" {
+			PrintUsage()
+			os.Exit(1)
+		}
+		MakeAnswers(config, os.Args[3])
 	case "rm":
 		CheckArgCount(1)
 		id, _ := strconv.Atoi(os.Args[2])
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	ScoringAll = "all" // the points of the group only if every test passes
+	ScoringMin = "min" // the points of the group times the lowest test score
+)
+
+// TestGroup is a subtask of the problem: a set of tests scored together.
+type TestGroup struct {
+	Name    string
+	Points  float64
+	Tests   string   // ids and ranges of ids, e.g. "1-5 8"
+	Scoring string   // ScoringAll (default) or ScoringMin
+	Depends []string // groups which must get all their points first
+}
+
+// GroupScore is the score of a group once its tests have run.
+type GroupScore struct {
+	Name   string
+	Points float64
+	Max    float64
+	Note   string // why the group got no points, if it's not because of its tests
+}
+
+// TestIds returns the ids of the tests of the group.
+func (group TestGroup) TestIds() ([]int, error) {
+	var ids []int
+	for _, s := range strings.Fields(group.Tests) {
+		r, err := parseTestRange(s)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, r...)
+	}
+	return ids, nil
+}
+
+// ValidateGroups checks that every group has existing tests and a valid
+// scoring rule, and only depends on groups defined before it.
+func ValidateGroups(config GocfConfig, session GocfSession) error {
+	seen := make(map[string]bool)
+	for _, group := range session.Groups {
+		if seen[group.Name] {
+			return errors.New("duplicate group: " + group.Name)
+		}
+		ids, err := group.TestIds()
+		if err != nil {
+			return fmt.Errorf("group %s: %v", group.Name, err)
+		}
+		if len(ids) == 0 {
+			return fmt.Errorf("group %s: no tests", group.Name)
+		}
+		for _, id := range ids {
+			if FileNotExist(inPath(config, id)) {
+				return fmt.Errorf("group %s: no test with id %d", group.Name, id)
+			}
+		}
+		if group.Scoring != "" && group.Scoring != ScoringAll && group.Scoring != ScoringMin {
+			return fmt.Errorf("group %s: unknown scoring rule: %s", group.Name, group.Scoring)
+		}
+		for _, dep := range group.Depends {
+			if !seen[dep] {
+				return fmt.Errorf("group %s: depends on %s, which is not defined before it", group.Name, dep)
+			}
+		}
+		seen[group.Name] = true
+	}
+	return nil
+}
+
+// testScore is the fraction of the points of a test which the result gets.
+// Partially correct tests score the fraction reported by the checker.
+func testScore(result TestResult) float64 {
+	switch result.Verdict {
+	case OK:
+		return 1
+	case PC:
+		return result.Check.Points
+	default:
+		return 0
+	}
+}
+
+// ScoreGroups computes the score of each group of the session from the
+// results of its tests. A group gets no points if it has no tests, if any of
+// its tests didn't run, or if a group it depends on didn't get all its points.
+func ScoreGroups(session GocfSession, results []TestResult) []GroupScore {
+	byId := make(map[int]TestResult)
+	for _, result := range results {
+		byId[result.Id] = result
+	}
+	full := make(map[string]bool)
+	var scores []GroupScore
+	for _, group := range session.Groups {
+		score := GroupScore{Name: group.Name, Max: group.Points}
+		ids, _ := group.TestIds()
+		fraction := 1.0
+		if len(ids) == 0 {
+			score.Note = "no tests"
+			fraction = 0
+		}
+		for _, id := range ids {
+			result, ok := byId[id]
+			if !ok {
+				score.Note = "not all tests were run"
+				fraction = 0
+				break
+			}
+			s := testScore(result)
+			if group.Scoring != ScoringMin && s < 1 {
+				s = 0
+			}
+			if s < fraction {
+				fraction = s
+			}
+		}
+		for _, dep := range group.Depends {
+			if !full[dep] && score.Note == "" {
+				score.Note = "depends on " + dep
+				fraction = 0
+			}
+		}
+		score.Points = group.Points * fraction
+		full[group.Name] = fraction == 1
+		scores = append(scores, score)
+	}
+	return scores
+}
+
+func totalScore(scores []GroupScore) (points, max float64) {
+	for _, score := range scores {
+		points += score.Points
+		max += score.Max
+	}
+	return
+}
+
+func printGroupScores(scores []GroupScore) {
+	for _, score := range scores {
+		fmt.Printf("  Group %s: %g/%g points", score.Name, score.Points, score.Max)
+		if score.Note != "" {
+			fmt.Printf(" (%s)", score.Note)
+		}
+		fmt.Println()
+	}
+	points, max := totalScore(scores)
+	fmt.Printf(" TOTAL: %g/%g points\n", points, max)
+}
+
+// formatTestRanges is the inverse of TestIds: it writes ids as ranges.
+func formatTestRanges(ids []int) string {
+	sort.Ints(ids)
+	var parts []string
+	for i := 0; i < len(ids); {
+		j := i
+		for j+1 < len(ids) && ids[j+1] <= ids[j]+1 {
+			j++
+		}
+		if ids[i] == ids[j] {
+			parts = append(parts, strconv.Itoa(ids[i]))
+		} else {
+			parts = append(parts, strconv.Itoa(ids[i])+"-"+strconv.Itoa(ids[j]))
+		}
+		i = j + 1
+	}
+	return strings.Join(parts, " ")
+}
+
+// removeGroupTest updates the groups once test #id is removed and the
+// following tests are renumbered.
+func removeGroupTest(session *GocfSession, id int) {
+	for i, group := range session.Groups {
+		ids, err := group.TestIds()
+		if err != nil {
+			continue
+		}
+		var kept []int
+		for _, t := range ids {
+			if t < id {
+				kept = append(kept, t)
+			} else if t > id {
+				kept = append(kept, t-1)
+			}
+		}
+		session.Groups[i].Tests = formatTestRanges(kept)
+	}
+}
+
+func groupsString(session GocfSession) string {
+	s := ""
+	for _, group := range session.Groups {
+		scoring := group.Scoring
+		if scoring == "" {
+			scoring = ScoringAll
+		}
+		s += fmt.Sprintf("  Group:      %s [tests %s, %g points, %s]", group.Name, group.Tests, group.Points, scoring)
+		if len(group.Depends) > 0 {
+			s += " depends on " + strings.Join(group.Depends, ", ")
+		}
+		s += "\n"
+	}
+	return s
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestScoreGroups(t *testing.T) {
+	results := []TestResult{
+		{Id: 1, Verdict: OK},
+		{Id: 2, Verdict: OK},
+		{Id: 3, Verdict: PC, Check: CheckOutcome{Points: 0.5}},
+		{Id: 4, Verdict: OK},
+		{Id: 5, Verdict: WA},
+		{Id: 6, Verdict: OK},
+	}
+	session := GocfSession{Groups: []TestGroup{
+		{Name: "samples", Points: 0, Tests: "1-2"},
+		{Name: "all", Points: 10, Tests: "1-4"},
+		{Name: "min", Points: 10, Tests: "1-4", Scoring: ScoringMin},
+		{Name: "full", Points: 20, Tests: "1 2 4", Depends: []string{"samples"}},
+		{Name: "wrong", Points: 20, Tests: "4-5", Scoring: ScoringMin},
+		{Name: "after-partial", Points: 20, Tests: "6", Depends: []string{"samples", "min"}},
+		{Name: "after-wrong", Points: 20, Tests: "6", Depends: []string{"wrong"}},
+		{Name: "not-run", Points: 10, Tests: "6-7"},
+		{Name: "empty", Points: 10},
+		{Name: "after-empty", Points: 10, Tests: "1", Depends: []string{"empty"}},
+	}}
+	want := []GroupScore{
+		{Name: "samples", Points: 0, Max: 0},
+		{Name: "all", Points: 0, Max: 10},
+		{Name: "min", Points: 5, Max: 10},
+		{Name: "full", Points: 20, Max: 20},
+		{Name: "wrong", Points: 0, Max: 20},
+		{Name: "after-partial", Points: 0, Max: 20, Note: "depends on min"},
+		{Name: "after-wrong", Points: 0, Max: 20, Note: "depends on wrong"},
+		{Name: "not-run", Points: 0, Max: 10, Note: "not all tests were run"},
+		{Name: "empty", Points: 0, Max: 10, Note: "no tests"},
+		{Name: "after-empty", Points: 0, Max: 10, Note: "depends on empty"},
+	}
+	scores := ScoreGroups(session, results)
+	if !reflect.DeepEqual(scores, want) {
+		t.Errorf("ScoreGroups() = %+v; want %+v", scores, want)
+	}
+	if points, max := totalScore(scores); points != 25 || max != 130 {
+		t.Errorf("totalScore() = %g, %g; want 25, 130", points, max)
+	}
+}
+
+func TestValidateGroups(t *testing.T) {
+	config := GocfConfig{SessionDir: t.TempDir()}
+	for id := 1; id <= 3; id++ {
+		ioutil.WriteFile(inPath(config, id), nil, os.ModePerm)
+	}
+	tests := []struct {
+		groups []TestGroup
+		err    string
+	}{
+		{[]TestGroup{{Name: "a", Tests: "1"}, {Name: "b", Tests: "2-3", Scoring: ScoringMin, Depends: []string{"a"}}}, ""},
+		{[]TestGroup{{Name: "a", Tests: "1"}, {Name: "a", Tests: "2"}}, "duplicate group: a"},
+		{[]TestGroup{{Name: "a"}}, "group a: no tests"},
+		{[]TestGroup{{Name: "a", Tests: "2-4"}}, "group a: no test with id 4"},
+		{[]TestGroup{{Name: "a", Tests: "x"}}, "group a: expected a positive number, found: x"},
+		{[]TestGroup{{Name: "a", Tests: "3-1"}}, "group a: invalid test range: 3-1"},
+		{[]TestGroup{{Name: "a", Tests: "1-1000000000000"}}, "group a: invalid test range: 1-1000000000000 (test ids go up to 100000)"},
+		{[]TestGroup{{Name: "a", Tests: "1", Scoring: "max"}}, "group a: unknown scoring rule: max"},
+		{[]TestGroup{{Name: "a", Tests: "1", Depends: []string{"b"}}, {Name: "b", Tests: "2"}},
+			"group a: depends on b, which is not defined before it"},
+	}
+	for _, test := range tests {
+		err := ValidateGroups(config, GocfSession{Groups: test.groups})
+		if (err == nil) != (test.err == "") || (err != nil && err.Error() != test.err) {
+			t.Errorf("ValidateGroups(%+v) = %v; want %q", test.groups, err, test.err)
+		}
+	}
+}
+
+func TestTestRanges(t *testing.T) {
+	tests := []struct {
+		tests string
+		ids   []int
+		str   string
+	}{
+		{"1-5 8", []int{1, 2, 3, 4, 5, 8}, "1-5 8"},
+		{"3 1 2", []int{3, 1, 2}, "1-3"},
+		{"7-7 9-10", []int{7, 9, 10}, "7 9-10"},
+		{"", nil, ""},
+	}
+	for _, test := range tests {
+		ids, err := TestGroup{Tests: test.tests}.TestIds()
+		if err != nil || !reflect.DeepEqual(ids, test.ids) {
+			t.Errorf("TestIds(%q) = %v, %v; want %v", test.tests, ids, err, test.ids)
+		}
+		if str := formatTestRanges(ids); str != test.str {
+			t.Errorf("formatTestRanges(%v) = %q; want %q", test.ids, str, test.str)
+		}
+	}
+}
+
+func TestRemoveGroupTest(t *testing.T) {
+	session := GocfSession{Groups: []TestGroup{{Name: "a", Tests: "1-3"}, {Name: "b", Tests: "3-5 7"}, {Name: "c", Tests: "3"}}}
+	removeGroupTest(&session, 3)
+	for i, want := range []string{"1-2", "3-4 6", ""} {
+		if session.Groups[i].Tests != want {
+			t.Errorf("group %s: tests %q; want %q", session.Groups[i].Name, session.Groups[i].Tests, want)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// how many bytes of the interaction are saved for each test
+const TranscriptLimit = 4 << 20
+
+// transcript records the data exchanged between the solution and the
+// interactor. Each line is prefixed by its direction: "> " for data sent by
+// the solution and "< " for data sent by the interactor. Only the beginning
+// of the interaction is recorded, up to TranscriptLimit bytes.
+type transcript struct {
+	mu        sync.Mutex
+	w         io.Writer
+	lastDir   string
+	lineStart bool
+	logged    int
+}
+
+func (t *transcript) log(dir string, p []byte) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.logged >= TranscriptLimit {
+		return
+	}
+	if t.logged+len(p) >= TranscriptLimit {
+		p = p[:TranscriptLimit-t.logged]
+		defer t.w.Write([]byte("\n... (truncated)\n"))
+	}
+	t.logged += len(p)
+	if dir != t.lastDir && !t.lineStart && t.lastDir != "" {
+		t.w.Write([]byte("\n"))
+		t.lineStart = true
+	}
+	t.lastDir = dir
+	for len(p) > 0 {
+		if t.lineStart {
+			t.w.Write([]byte(dir))
+		}
+		n := len(p)
+		for i, b := range p {
+			if b == '\n' {
+				n = i + 1
+				break
+			}
+		}
+		t.w.Write(p[:n])
+		t.lineStart = p[n-1] == '\n'
+		p = p[n:]
+	}
+}
+
+// sentCounter counts the bytes sent by the solution, which are limited like
+// its output is for other tasks.
+type sentCounter struct {
+	limit int64
+	sent  int64
+}
+
+func (c *sentCounter) exceeded() bool {
+	return c.limit > 0 && atomic.LoadInt64(&c.sent) > c.limit
+}
+
+// relay copies everything from src to dst, logging it to the transcript.
+// If dst is closed by the other side, the rest of src is still consumed so
+// that the writer never blocks. dst is closed once src is exhausted. If
+// counter is not nil, the bytes are counted, and no longer relayed once over
+// the limit.
+func relay(dst io.WriteCloser, src io.Reader, t *transcript, dir string, counter *sentCounter) {
+	defer dst.Close()
+	buf := make([]byte, 4096)
+	broken := false
+	for {
+		n, err := src.Read(buf)
+		if counter != nil {
+			atomic.AddInt64(&counter.sent, int64(n))
+		}
+		if n > 0 && (counter == nil || !counter.exceeded()) {
+			t.log(dir, buf[:n])
+			if !broken {
+				if _, werr := dst.Write(buf[:n]); werr != nil {
+					broken = true
+				}
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+func logPath(poolDir string, id int) string {
+	return poolDir + "/" + strconv.Itoa(id) + ".log"
+}
+
+// TestInteractive runs test #id of an interactive problem. The solution and
+// the interactor talk through their standard streams, and the verdict is
+// decided by the exit code of the interactor (following the testlib
+// protocol), which receives the test input, the file to write its output to
+// and the answer file.
+func TestInteractive(config GocfConfig, session GocfSession, programs Programs, id int) TestResult {
+	poolDir := config.SessionDir + "/" + TestPoolDir
+	dir := runDir(config, id)
+	os.MkdirAll(dir, os.ModePerm)
+	defer os.RemoveAll(dir)
+
+	inputFile := poolDir + "/" + strconv.Itoa(id) + ".in"
+	outputFile := poolDir + "/" + strconv.Itoa(id) + ".out"
+	answerFile := poolDir + "/" + strconv.Itoa(id) + ".ans"
+	args := []string{inputFile, outputFile}
+	if FileExists(answerFile) {
+		args = append(args, answerFile)
+	}
+
+	logFile, _ := os.Create(logPath(poolDir, id))
+	defer logFile.Close()
+	t := &transcript{w: logFile, lineStart: true}
+
+	sol := exec.Command(programs.Solution[0], programs.Solution[1:]...)
+	sol.Dir = dir
+	if session.Sandbox {
+		if err := sandbox(sol, dir); err != nil {
+			return TestResult{Id: id, Verdict: RTE, Stats: RunStats{ExitCode: -1}}
+		}
+	}
+	interactor := programs.Interactor
+	inter := exec.Command(interactor[0], append(append([]string{}, interactor[1:]...), args...)...)
+	inter.Dir = dir
+	interErr := &truncBuffer{limit: PrintLimit}
+	inter.Stderr = interErr
+
+	solOut, solOutW, _ := os.Pipe()
+	interIn, interInW, _ := os.Pipe()
+	interOut, interOutW, _ := os.Pipe()
+	solIn, solInW, _ := os.Pipe()
+	sol.Stdout, sol.Stdin = solOutW, solIn
+	inter.Stdout, inter.Stdin = interOutW, interIn
+
+	sent := &sentCounter{limit: int64(session.OutputLimit)}
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		relay(interInW, solOut, t, "> ", sent)
+	}()
+	go func() {
+		defer wg.Done()
+		relay(solInW, interOut, t, "< ", nil)
+	}()
+
+	setProcessGroup(inter)
+	interDone := make(chan error, 1)
+	if err := inter.Start(); err != nil {
+		interDone <- err
+	} else {
+		defer killGroup(inter)
+		go func() { interDone <- inter.Wait() }()
+	}
+	// the children hold their own copies of these
+	interIn.Close()
+	interOutW.Close()
+
+	result, stats := run(sol, session, sent)
+	// now the relays can see the end of the solution streams
+	solOutW.Close()
+	solIn.Close()
+
+	var timeout <-chan time.Time
+	if session.IdleTimeout() > 0 {
+		timeout = time.After(session.IdleTimeout())
+	}
+	var err error
+	select {
+	case err = <-interDone:
+	case <-timeout:
+		killGroup(inter)
+		err = <-interDone
+		if result == OK {
+			result = ILE
+		}
+	}
+	solOut.Close()
+	interOut.Close()
+	wg.Wait()
+
+	if result != OK && result != RTE {
+		return TestResult{Id: id, Verdict: result, Stats: stats}
+	}
+	if inter.ProcessState == nil || inter.ProcessState.ExitCode() < 0 {
+		return TestResult{Id: id, Verdict: FAIL, Stats: stats, Check: CheckOutcome{Message: "interactor failed: " + err.Error()}}
+	}
+	// the interactor knows better why the interaction went wrong
+	verdict, outcome := testlibVerdict(inter.ProcessState.ExitCode(), interErr.String())
+	if verdict == OK && result == RTE {
+		verdict = RTE
+	}
+	return TestResult{Id: id, Verdict: verdict, Stats: stats, Check: outcome}
+}
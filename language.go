@@ -0,0 +1,148 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// LanguageProfile describes how to build and run programs written in a
+// language. Commands are templates where {src} is the source file, {bin} the
+// build output, {class} the source file name without extension and {flags}
+// expands to the extra compiler flags of the session.
+type LanguageProfile struct {
+	Name       string
+	Extensions []string
+	Compile    []string // empty for interpreted languages
+	OutputDir  bool     // the compiler writes a directory at {bin} instead of a file
+	Run        []string
+	Version    []string // prints the compiler version, which is part of the build cache key
+	Includes   []string // extensions of the files next to the source which it can include
+	Template   func(session GocfSession) string
+}
+
+var Languages = []LanguageProfile{
+	{
+		Name:       "go",
+		Extensions: []string{".go"},
+		Compile:    []string{"go", "build", "{flags}", "-o", "{bin}", "{src}"},
+		Run:        []string{"{bin}"},
+		Version:    []string{"go", "version"},
+		Template:   GoTemplate,
+	},
+	{
+		Name:       "cpp",
+		Extensions: []string{".cpp", ".cc", ".cxx"},
+		Compile:    []string{"g++", "-std=c++17", "-O2", "{flags}", "-o", "{bin}", "{src}"},
+		Run:        []string{"{bin}"},
+		Version:    []string{"g++", "--version"},
+		Includes:   []string{".h", ".hh", ".hpp", ".hxx", ".inc"},
+		Template:   CppTemplate,
+	},
+	{
+		Name:       "python",
+		Extensions: []string{".py"},
+		Run:        []string{"python3", "{src}"},
+		Template:   PythonTemplate,
+	},
+	{
+		Name:       "java",
+		Extensions: []string{".java"},
+		Compile:    []string{"javac", "{flags}", "-d", "{bin}", "{src}"},
+		OutputDir:  true,
+		Run:        []string{"java", "-Xss64m", "-cp", "{bin}", "Main"},
+		Version:    []string{"javac", "-version"},
+		Template:   JavaTemplate,
+	},
+	{
+		Name:       "rust",
+		Extensions: []string{".rs"},
+		Compile:    []string{"rustc", "--edition", "2021", "-O", "{flags}", "-o", "{bin}", "{src}"},
+		Run:        []string{"{bin}"},
+		Version:    []string{"rustc", "--version"},
+		Includes:   []string{".rs"},
+		Template:   RustTemplate,
+	},
+}
+
+func LanguageByName(name string) (LanguageProfile, error) {
+	for _, lang := range Languages {
+		if lang.Name == name {
+			return lang, nil
+		}
+	}
+	return LanguageProfile{}, errors.New("unsupported language: " + name)
+}
+
+func LanguageByFile(path string) (LanguageProfile, error) {
+	ext := filepath.Ext(path)
+	for _, lang := range Languages {
+		for _, e := range lang.Extensions {
+			if e == ext {
+				return lang, nil
+			}
+		}
+	}
+	return LanguageProfile{}, errors.New("no language for file: " + path)
+}
+
+// SessionLanguage returns the language of the session, or the one matching
+// the work file extension if the session doesn't set one. Go is assumed for
+// unknown extensions, but a language set by the session must be supported,
+// and match the work file extension, as compilers go by it.
+func SessionLanguage(config GocfConfig, session GocfSession) (LanguageProfile, error) {
+	if session.Language != "*" && session.Language != "" {
+		lang, err := LanguageByName(session.Language)
+		if err == nil && !hasExtension(config.WorkFile, lang.Extensions) {
+			err = fmt.Errorf("the work file %s doesn't have a %s extension (%s)", config.WorkFile, lang.Name,
+				strings.Join(lang.Extensions, ", "))
+		}
+		return lang, err
+	}
+	if lang, err := LanguageByFile(config.WorkFile); err == nil {
+		return lang, nil
+	}
+	return LanguageByName("go")
+}
+
+func expandCommand(template []string, src, bin string, flags []string) []string {
+	class := strings.TrimSuffix(filepath.Base(src), filepath.Ext(src))
+	r := strings.NewReplacer("{src}", src, "{bin}", bin, "{class}", class)
+	var cmd []string
+	for _, arg := range template {
+		if arg == "{flags}" {
+			cmd = append(cmd, flags...)
+		} else {
+			cmd = append(cmd, r.Replace(arg))
+		}
+	}
+	return cmd
+}
+
+// Build compiles src into bin, and returns the command running the program.
+// On failure, the compiler output is returned as well.
+func (lang LanguageProfile) Build(src, bin string, flags []string) ([]string, string, error) {
+	src, err := filepath.Abs(src)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(lang.Compile) > 0 {
+		os.RemoveAll(bin)
+		if lang.OutputDir {
+			os.MkdirAll(bin, os.ModePerm)
+		}
+		args := expandCommand(lang.Compile, src, bin, flags)
+		cmd := exec.Command(args[0], args[1:]...)
+		var out bytes.Buffer
+		cmd.Stdout = &out
+		cmd.Stderr = &out
+		if err := cmd.Run(); err != nil {
+			return nil, out.String(), err
+		}
+	}
+	return expandCommand(lang.Run, src, bin, nil), "", nil
+}
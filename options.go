@@ -0,0 +1,265 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	FormatText  = "text"
+	FormatJSON  = "json"
+	FormatJUnit = "junit"
+)
+
+type TestOptions struct {
+	Jobs     int       // number of tests run at the same time
+	Tests    []int     // ids of the tests to run, all of them if empty
+	FailFast bool      // stop at the first test not passing
+	Rebuild  bool      // build the work file even if a cached build exists
+	Format   string    // format of the results
+	Report   io.Writer // where json and junit results are written
+	Repeat   int       // number of times each test is run
+}
+
+func DefaultTestOptions() TestOptions {
+	return TestOptions{
+		Jobs:   1,
+		Format: FormatText,
+		Repeat: 1,
+	}
+}
+
+func parseFormat(s string) (string, error) {
+	switch s {
+	case FormatText, FormatJSON, FormatJUnit:
+		return s, nil
+	}
+	return "", errors.New("unknown format: " + s)
+}
+
+func parsePositive(s string) (int, error) {
+	n, err := strconv.Atoi(s)
+	if err != nil || n <= 0 {
+		return 0, errors.New("expected a positive number, found: " + s)
+	}
+	return n, nil
+}
+
+// the largest test id accepted, so that a mistyped range can't exhaust the memory
+const MaxTestId = 100000
+
+// parseTestRange parses a test id ("3") or an inclusive range of ids ("5-7").
+func parseTestRange(s string) ([]int, error) {
+	parts := strings.SplitN(s, "-", 2)
+	from, err := parsePositive(parts[0])
+	if err != nil {
+		return nil, err
+	}
+	to := from
+	if len(parts) == 2 {
+		if to, err = parsePositive(parts[1]); err != nil {
+			return nil, err
+		}
+	}
+	if from > to {
+		return nil, errors.New("invalid test range: " + s)
+	}
+	if to > MaxTestId {
+		return nil, fmt.Errorf("invalid test range: %s (test ids go up to %d)", s, MaxTestId)
+	}
+	var ids []int
+	for id := from; id <= to; id++ {
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func ParseTestOptions(args []string) (opts TestOptions, err error) {
+	opts = DefaultTestOptions()
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "-j":
+			if i+1 == len(args) {
+				return opts, errors.New("missing value for -j")
+			}
+			i++
+			if opts.Jobs, err = parsePositive(args[i]); err != nil {
+				return
+			}
+		case strings.HasPrefix(arg, "-j"):
+			if opts.Jobs, err = parsePositive(arg[2:]); err != nil {
+				return
+			}
+		case arg == "--fail-fast":
+			opts.FailFast = true
+		case arg == "--rebuild":
+			opts.Rebuild = true
+		case arg == "--repeat":
+			if i+1 == len(args) {
+				return opts, errors.New("missing value for --repeat")
+			}
+			i++
+			if opts.Repeat, err = parsePositive(args[i]); err != nil {
+				return
+			}
+		case arg == "--format":
+			if i+1 == len(args) {
+				return opts, errors.New("missing value for --format")
+			}
+			i++
+			if opts.Format, err = parseFormat(args[i]); err != nil {
+				return
+			}
+		case strings.HasPrefix(arg, "--format="):
+			if opts.Format, err = parseFormat(strings.TrimPrefix(arg, "--format=")); err != nil {
+				return
+			}
+		case strings.HasPrefix(arg, "-"):
+			return opts, errors.New("unrecognized argument: " + arg)
+		default:
+			ids, err := parseTestRange(arg)
+			if err != nil {
+				return opts, err
+			}
+			opts.Tests = append(opts.Tests, ids...)
+		}
+	}
+	return
+}
+
+type StressOptions struct {
+	Iterations int           // maximum number of generated tests
+	Duration   time.Duration // maximum total duration, 0 means no limit
+}
+
+func DefaultStressOptions() StressOptions {
+	return StressOptions{
+		Iterations: 1000,
+	}
+}
+
+// ParseStressOptions parses the arguments of the stress command: the
+// generator, the brute-force solution and the budget options.
+func ParseStressOptions(args []string) (gen, brute string, opts StressOptions, err error) {
+	opts = DefaultStressOptions()
+	var programs []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch arg {
+		case "-n", "--iterations", "--time":
+			if i+1 == len(args) {
+				return gen, brute, opts, errors.New("missing value for " + arg)
+			}
+			i++
+			n, perr := parsePositive(args[i])
+			if perr != nil {
+				return gen, brute, opts, perr
+			}
+			if arg == "--time" {
+				opts.Duration = time.Duration(n) * time.Second
+			} else {
+				opts.Iterations = n
+			}
+		default:
+			if strings.HasPrefix(arg, "-") {
+				return gen, brute, opts, errors.New("unrecognized argument: " + arg)
+			}
+			programs = append(programs, arg)
+		}
+	}
+	if len(programs) != 2 {
+		return gen, brute, opts, errors.New("expected a generator and a brute-force solution")
+	}
+	return programs[0], programs[1], opts, nil
+}
+
+type BenchOptions struct {
+	Max    int   // the maximum n of the problem, to extrapolate to
+	Sizes  []int // the values of n to run the solution on
+	Repeat int   // runs at each size, of which the fastest is kept
+}
+
+// defaultBenchSizes doubles n up to half the maximum, starting from 1/64 of it.
+func defaultBenchSizes(max int) []int {
+	var sizes []int
+	for d := 64; d >= 2; d /= 2 {
+		if n := max / d; n > 0 && (len(sizes) == 0 || n > sizes[len(sizes)-1]) {
+			sizes = append(sizes, n)
+		}
+	}
+	return sizes
+}
+
+// parseSize parses a value of n, either as an integer or in scientific
+// notation, as constraints are often given (e.g. 2e5).
+func parseSize(s string) (int, error) {
+	if n, err := parsePositive(s); err == nil {
+		return n, nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || f < 1 || f > math.MaxInt32 || f != math.Trunc(f) {
+		return 0, errors.New("expected a positive integer, found: " + s)
+	}
+	return int(f), nil
+}
+
+// ParseBenchOptions parses the arguments of the bench command: the
+// generator, the maximum n and the sizes to run.
+func ParseBenchOptions(args []string) (gen string, opts BenchOptions, err error) {
+	opts.Repeat = 3
+	var programs []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch arg {
+		case "--max", "--sizes", "--repeat":
+			if i+1 == len(args) {
+				return gen, opts, errors.New("missing value for " + arg)
+			}
+			i++
+			switch arg {
+			case "--max":
+				opts.Max, err = parseSize(args[i])
+			case "--sizes":
+				opts.Sizes = nil
+				for _, s := range strings.Split(args[i], ",") {
+					var n int
+					if n, err = parseSize(s); err != nil {
+						break
+					}
+					opts.Sizes = append(opts.Sizes, n)
+				}
+			case "--repeat":
+				opts.Repeat, err = parsePositive(args[i])
+			}
+			if err != nil {
+				return gen, opts, err
+			}
+		default:
+			if strings.HasPrefix(arg, "-") {
+				return gen, opts, errors.New("unrecognized argument: " + arg)
+			}
+			programs = append(programs, arg)
+		}
+	}
+	if len(programs) != 1 {
+		return gen, opts, errors.New("expected a generator")
+	}
+	if opts.Max == 0 {
+		return gen, opts, errors.New("missing the maximum n (--max)")
+	}
+	if opts.Sizes == nil {
+		opts.Sizes = defaultBenchSizes(opts.Max)
+	}
+	sort.Ints(opts.Sizes)
+	if len(opts.Sizes) < 3 {
+		return gen, opts, errors.New("expected at least 3 sizes to fit the complexity")
+	}
+	return programs[0], opts, nil
+}
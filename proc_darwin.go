@@ -0,0 +1,32 @@
+package main
+
+import (
+	"os"
+	"syscall"
+	"time"
+)
+
+// currentCPUTime returns the user and system time consumed so far by a running process.
+// It isn't sampled on OS X, so CPU-bound solutions are only stopped by the idle limit.
+func currentCPUTime(pid int) time.Duration {
+	return 0
+}
+
+// peakMemory returns the maximum resident set size of a finished process, in bytes.
+func peakMemory(state *os.ProcessState) int64 {
+	if ru, ok := state.SysUsage().(*syscall.Rusage); ok {
+		return int64(ru.Maxrss) // reported in bytes on OS X
+	}
+	return 0
+}
+
+// currentPeakMemory returns the peak resident set size of a running process, in bytes.
+// There is no cheap way to sample it on OS X, so the limit is only checked on exit.
+func currentPeakMemory(pid int) int64 {
+	return 0
+}
+
+// securityViolation tells whether a process was killed by the sandbox.
+func securityViolation(state *os.ProcessState) bool {
+	return false
+}
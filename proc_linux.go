@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bufio"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// clockTicks is the unit of the times reported in /proc/<pid>/stat (USER_HZ),
+// which is 100 on every mainstream Linux platform.
+const clockTicks = 100
+
+// currentCPUTime returns the user and system time consumed so far by a running process.
+func currentCPUTime(pid int) time.Duration {
+	b, err := ioutil.ReadFile("/proc/" + strconv.Itoa(pid) + "/stat")
+	if err != nil {
+		return 0
+	}
+	// the command name may contain spaces, so skip past its closing parenthesis
+	s := string(b)
+	fields := strings.Fields(s[strings.LastIndex(s, ")")+1:])
+	if len(fields) < 13 {
+		return 0
+	}
+	utime, _ := strconv.ParseInt(fields[11], 10, 64)
+	stime, _ := strconv.ParseInt(fields[12], 10, 64)
+	return time.Duration(utime+stime) * time.Second / clockTicks
+}
+
+// peakMemory returns the maximum resident set size of a finished process, in bytes.
+func peakMemory(state *os.ProcessState) int64 {
+	if ru, ok := state.SysUsage().(*syscall.Rusage); ok {
+		return int64(ru.Maxrss) * 1024 // reported in KiB on Linux
+	}
+	return 0
+}
+
+// currentPeakMemory returns the peak resident set size of a running process, in bytes.
+func currentPeakMemory(pid int) int64 {
+	f, err := os.Open("/proc/" + strconv.Itoa(pid) + "/status")
+	if err != nil {
+		return 0
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 3 && fields[0] == "VmHWM:" {
+			kb, _ := strconv.ParseInt(fields[1], 10, 64)
+			return kb * 1024
+		}
+	}
+	return 0
+}
+
+// securityViolation tells whether a process was killed by the sandbox.
+func securityViolation(state *os.ProcessState) bool {
+	if state == nil {
+		return false
+	}
+	if ws, ok := state.Sys().(syscall.WaitStatus); ok {
+		return ws.Signaled() && ws.Signal() == syscall.SIGSYS
+	}
+	return false
+}
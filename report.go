@@ -0,0 +1,231 @@
+package main
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// TestReport is the result of a single test, as written by the json format.
+// Times are in seconds and memory in bytes. Points is the fraction of the
+// points of the test which the solution gets, from 0 to 1.
+type TestReport struct {
+	Id       int
+	Verdict  string
+	Points   float64
+	CPUTime  float64
+	WallTime float64
+	Memory   int64
+	ExitCode int
+	Signal   string
+	Cause    string // why the solution crashed, for runtime errors
+	Trace    string // panic message and top stack frames, for Go panics
+	Stderr   string // the end of the standard error
+	Checker  string // checker comment
+	Input    string
+	Output   string
+	Answer   string        `json:",omitempty"` // only if the test has an answer
+	Log      string        `json:",omitempty"` // interaction log
+	Timing   *TimingReport `json:",omitempty"`
+}
+
+// TimingReport summarizes the CPU time of a test run several times, in
+// seconds. Margin is what the slowest run had left before the time limit.
+type TimingReport struct {
+	Runs     int
+	Min      float64
+	Median   float64
+	Max      float64
+	Margin   float64
+	Unstable bool
+}
+
+type SummaryReport struct {
+	Tests     int
+	Passed    int
+	Failed    int
+	Unchecked int     // tests without an answer
+	Points    float64 `json:",omitempty"` // total of the groups
+	MaxPoints float64 `json:",omitempty"`
+}
+
+type Report struct {
+	Contest string
+	Task    string
+	Tests   []TestReport
+	Summary SummaryReport
+	Groups  []GroupScore `json:",omitempty"`
+}
+
+func BuildReport(config GocfConfig, session GocfSession, results []TestResult) Report {
+	testDir := config.SessionDir + "/" + TestPoolDir
+	report := Report{Contest: session.Contest, Task: session.Task, Tests: []TestReport{}}
+	for _, result := range results {
+		prefix := testDir + "/" + strconv.Itoa(result.Id)
+		test := TestReport{
+			Id:       result.Id,
+			Verdict:  result.Verdict.String(),
+			Points:   testScore(result),
+			CPUTime:  result.Stats.CPUTime.Seconds(),
+			WallTime: result.Stats.WallTime.Seconds(),
+			Memory:   result.Stats.Memory,
+			ExitCode: result.Stats.ExitCode,
+			Signal:   result.Stats.Signal,
+			Cause:    result.Cause,
+			Trace:    result.Trace,
+			Stderr:   result.Stats.Stderr,
+			Checker:  result.Check.Message,
+			Input:    prefix + ".in",
+			Output:   prefix + ".out",
+		}
+		if FileExists(prefix + ".ans") {
+			test.Answer = prefix + ".ans"
+		}
+		if session.Interactor != "" {
+			test.Log = logPath(testDir, result.Id)
+		}
+		if t := result.Timing; t != nil {
+			test.Timing = &TimingReport{
+				Runs:     t.Runs,
+				Min:      t.Min.Seconds(),
+				Median:   t.Median.Seconds(),
+				Max:      t.Max.Seconds(),
+				Margin:   t.Margin(session).Seconds(),
+				Unstable: t.Unstable,
+			}
+		}
+		report.Tests = append(report.Tests, test)
+		switch result.Verdict {
+		case OK:
+			report.Summary.Passed++
+		case UNCHECKED:
+			report.Summary.Unchecked++
+		default:
+			report.Summary.Failed++
+		}
+	}
+	report.Summary.Tests = len(results)
+	if len(session.Groups) > 0 {
+		report.Groups = ScoreGroups(session, results)
+		report.Summary.Points, report.Summary.MaxPoints = totalScore(report.Groups)
+	}
+	return report
+}
+
+func WriteJSONReport(w io.Writer, config GocfConfig, session GocfSession, results []TestResult) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(BuildReport(config, session, results))
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitProblem struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+type junitTestCase struct {
+	Name       string          `xml:"name,attr"`
+	ClassName  string          `xml:"classname,attr"`
+	Time       string          `xml:"time,attr"`
+	Properties []junitProperty `xml:"properties>property"`
+	Failure    *junitProblem   `xml:"failure,omitempty"`
+	Error      *junitProblem   `xml:"error,omitempty"`
+	Skipped    *junitProblem   `xml:"skipped,omitempty"`
+	SystemErr  string          `xml:"system-err,omitempty"`
+}
+
+type junitTestSuite struct {
+	XMLName  xml.Name        `xml:"testsuite"`
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Errors   int             `xml:"errors,attr"`
+	Skipped  int             `xml:"skipped,attr"`
+	Time     string          `xml:"time,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+// WriteJUnitReport writes the results as a JUnit test suite. Failing tests
+// are reported as failures, except for checker failures, which are errors,
+// and tests without an answer are skipped.
+func WriteJUnitReport(w io.Writer, config GocfConfig, session GocfSession, results []TestResult) error {
+	testDir := config.SessionDir + "/" + TestPoolDir
+	name := session.Contest + "/" + session.Task
+	suite := junitTestSuite{Name: name, Tests: len(results)}
+	var total time.Duration
+	for _, result := range results {
+		total += result.Stats.WallTime
+		prefix := testDir + "/" + strconv.Itoa(result.Id)
+		c := junitTestCase{
+			Name:      "Test #" + strconv.Itoa(result.Id),
+			ClassName: name,
+			Time:      fmt.Sprintf("%.3f", result.Stats.WallTime.Seconds()),
+			Properties: []junitProperty{
+				{"verdict", result.Verdict.String()},
+				{"cpuTime", fmt.Sprintf("%.3f", result.Stats.CPUTime.Seconds())},
+				{"memory", strconv.FormatInt(result.Stats.Memory, 10)},
+				{"exitCode", strconv.Itoa(result.Stats.ExitCode)},
+				{"signal", result.Stats.Signal},
+				{"cause", result.Cause},
+				{"input", prefix + ".in"},
+				{"output", prefix + ".out"},
+			},
+			SystemErr: result.Stats.Stderr,
+		}
+		if FileExists(prefix + ".ans") {
+			c.Properties = append(c.Properties, junitProperty{"answer", prefix + ".ans"})
+		}
+		if session.Interactor != "" {
+			c.Properties = append(c.Properties, junitProperty{"log", logPath(testDir, result.Id)})
+		}
+		if t := result.Timing; t != nil {
+			c.Properties = append(c.Properties,
+				junitProperty{"runs", strconv.Itoa(t.Runs)},
+				junitProperty{"cpuMin", fmt.Sprintf("%.3f", t.Min.Seconds())},
+				junitProperty{"cpuMedian", fmt.Sprintf("%.3f", t.Median.Seconds())},
+				junitProperty{"cpuMax", fmt.Sprintf("%.3f", t.Max.Seconds())},
+				junitProperty{"margin", fmt.Sprintf("%.3f", t.Margin(session).Seconds())},
+				junitProperty{"unstable", strconv.FormatBool(t.Unstable)})
+		}
+		problem := &junitProblem{
+			Message: result.Detail(),
+			Type:    result.Verdict.String(),
+			Text:    result.Check.Message,
+		}
+		if result.Trace != "" {
+			problem.Text = result.Trace
+		}
+		switch result.Verdict {
+		case OK:
+		case UNCHECKED:
+			c.Skipped = &junitProblem{Message: "no answer to check the output against"}
+			suite.Skipped++
+		case FAIL:
+			c.Error = problem
+			suite.Errors++
+		default:
+			c.Failure = problem
+			suite.Failures++
+		}
+		suite.Cases = append(suite.Cases, c)
+	}
+	suite.Time = fmt.Sprintf("%.3f", total.Seconds())
+
+	io.WriteString(w, xml.Header)
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(suite); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
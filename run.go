@@ -2,26 +2,82 @@ package main
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"sort"
 	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
 	"time"
 )
 
+// Verdict is the outcome of a test.
+type Verdict int
+
 const (
-	OK = iota
+	OK Verdict = iota
 	WA
 	TLE
 	MLE
 	RTE
+	ILE
+	OLE
+	SV
+	PE
+	FAIL
+	PC
+	UNCHECKED // the test has no answer to check the output against
 )
 
 const TestPoolDir string = "__pool__"
 
-func ResultMsg(r int) string {
-	switch r {
+// how many bytes of each test file are shown in the results
+const PrintLimit = 4096
+
+// how often a running solution is sampled for resource usage
+const pollInterval = 5 * time.Millisecond
+
+// how long to wait for the output of a finished solution, in case something it
+// spawned keeps it open
+const waitDelay = time.Second
+
+// TestResult is everything known about a test once it has run.
+type TestResult struct {
+	Id      int
+	Verdict Verdict
+	Stats   RunStats
+	Check   CheckOutcome // checker (or interactor) comment and points
+	Cause   string       // why the solution crashed, for runtime errors
+	Trace   string       // panic message and top stack frames, for Go panics
+	Timing  *Timing      // only for tests run several times
+}
+
+// Detail describes the verdict of the test along with its reason.
+func (result TestResult) Detail() string {
+	if result.Cause != "" {
+		return result.Verdict.String() + " (" + result.Cause + ")"
+	}
+	return verdictDetail(result.Verdict, result.Check)
+}
+
+type RunStats struct {
+	CPUTime  time.Duration // user and system time
+	WallTime time.Duration
+	Memory   int64  // peak resident set size, in bytes
+	ExitCode int    // -1 if the process didn't exit normally
+	Signal   string // signal which terminated the process, if any
+	Stderr   string // the end of the standard error, unless redirected
+}
+
+func (v Verdict) String() string {
+	switch v {
 	case OK:
 		return "OK"
 	case WA:
@@ -32,11 +88,31 @@ func ResultMsg(r int) string {
 		return "Memory Limit Exceeded"
 	case RTE:
 		return "Runtime Error"
+	case ILE:
+		return "Idleness Limit Exceeded"
+	case OLE:
+		return "Output Limit Exceeded"
+	case SV:
+		return "Security Violation"
+	case PE:
+		return "Presentation Error"
+	case FAIL:
+		return "Checker Failed"
+	case PC:
+		return "Partially Correct"
+	case UNCHECKED:
+		return "UNCHECKED"
 	default:
-		panic("Unrecognized result code: " + strconv.Itoa(r))
+		panic("Unrecognized verdict: " + strconv.Itoa(int(v)))
 	}
 }
 
+// Failed tells whether the verdict is a failure. Tests without an answer are
+// not, though they can't be told to pass either.
+func (v Verdict) Failed() bool {
+	return v != OK && v != UNCHECKED
+}
+
 func firstAvailableId(config GocfConfig) (id int) {
 	id = 1
 	for FileExists(inPath(config, id)) {
@@ -66,13 +142,22 @@ func AddTest(config GocfConfig, input, answer []byte) int {
 	return id
 }
 
-func AddTestFromUser(config GocfConfig) {
+// AddTestFromUser adds a test read from the standard input. Unless validate
+// is false, the input is first checked by the session validator, if any.
+func AddTestFromUser(config GocfConfig, validate bool) {
 	session := LoadCurrentSession(config)
 	fmt.Println(session.String())
 	fmt.Println("\nEnter input:")
 	input, _ := ioutil.ReadAll(os.Stdin)
 	fmt.Println("\nEnter answer [empty if unknown]:")
 	answer, _ := ioutil.ReadAll(os.Stdin)
+	if validate && session.Validator != "" {
+		validator := RequireValidator(config, session)
+		if err := validateInput(validator, input); err != nil {
+			fmt.Println("Invalid input:", err)
+			os.Exit(1)
+		}
+	}
 	id := AddTest(config, input, answer)
 	fmt.Println("Added test #", id)
 }
@@ -87,11 +172,21 @@ func RemoveTest(config GocfConfig, id int) {
 	if FileExists(ansPath(config, id)) {
 		os.Remove(ansPath(config, id))
 	}
+	if FileExists(genPath(config, id)) {
+		os.Remove(genPath(config, id))
+	}
+	if session := LoadCurrentSession(config); len(session.Groups) > 0 {
+		removeGroupTest(&session, id)
+		session.Save(config)
+	}
 	for FileExists(inPath(config, id+1)) {
 		os.Rename(inPath(config, id+1), inPath(config, id))
 		if FileExists(ansPath(config, id+1)) {
 			os.Rename(ansPath(config, id+1), ansPath(config, id))
 		}
+		if FileExists(genPath(config, id+1)) {
+			os.Rename(genPath(config, id+1), genPath(config, id))
+		}
 		id++
 	}
 }
@@ -119,134 +214,513 @@ func PopulateTestDir(config GocfConfig, session GocfSession) {
 	}
 }
 
-func Compile(config GocfConfig, session GocfSession) {
-	// TODO support other languages?
-	bin := config.SessionDir + "/" + TestPoolDir + "/solution"
-	if FileExists(bin) {
-		os.Remove(bin)
-	}
-	cmd := exec.Command("go", "build", "-o", bin, config.WorkFile)
-	var out bytes.Buffer
-	cmd.Stderr = &out
-	err := cmd.Run()
+// Compile builds the work file, unless it was already built with the same
+// flags and compiler, and returns the command running the solution.
+func Compile(config GocfConfig, session GocfSession, rebuild bool) []string {
+	solution, cached, out, err := buildSolution(config, session, rebuild)
 	if err != nil {
 		fmt.Println("Compilation error")
-		fmt.Println(out.String())
+		fmt.Println(out)
 		os.Exit(1)
 	}
+	if cached {
+		fmt.Println("Work file unchanged, using cached build")
+	}
+	return solution
 }
 
-func run(cmd *exec.Cmd, session GocfSession) int {
+func buildSolution(config GocfConfig, session GocfSession, rebuild bool) ([]string, bool, string, error) {
+	lang, err := SessionLanguage(config, session)
+	if err != nil {
+		return nil, false, err.Error(), err
+	}
+	return lang.BuildCached(config.WorkFile, session.CompileFlags, rebuild)
+}
+
+// outputLimiter tells whether a running solution has written more output
+// than the session allows.
+type outputLimiter interface {
+	exceeded() bool
+}
+
+// limitWriter writes up to limit bytes to w, and then fails.
+type limitWriter struct {
+	w       io.Writer
+	limit   int64
+	written int64
+	over    int32
+}
+
+func (lw *limitWriter) Write(p []byte) (int, error) {
+	if lw.written+int64(len(p)) > lw.limit {
+		atomic.StoreInt32(&lw.over, 1)
+		return 0, errors.New("output limit exceeded")
+	}
+	lw.written += int64(len(p))
+	return lw.w.Write(p)
+}
+
+func (lw *limitWriter) exceeded() bool {
+	return atomic.LoadInt32(&lw.over) != 0
+}
+
+// fileLimiter checks the size of an output file written directly by the solution.
+type fileLimiter struct {
+	path  string
+	limit int64
+}
+
+func (fl fileLimiter) exceeded() bool {
+	info, err := os.Stat(fl.path)
+	return err == nil && info.Size() > fl.limit
+}
+
+// truncBuffer keeps up to limit bytes of what is written to it, and silently
+// drops the rest.
+type truncBuffer struct {
+	mu    sync.Mutex
+	buf   bytes.Buffer
+	limit int
+}
+
+func (tb *truncBuffer) Write(p []byte) (int, error) {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	if room := tb.limit - tb.buf.Len(); room > 0 {
+		if len(p) > room {
+			tb.buf.Write(p[:room])
+		} else {
+			tb.buf.Write(p)
+		}
+	}
+	return len(p), nil
+}
+
+func (tb *truncBuffer) String() string {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	return tb.buf.String()
+}
+
+// setProcessGroup makes cmd the leader of its own process group, so that
+// anything it spawns can be killed along with it.
+func setProcessGroup(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Setpgid = true
+	cmd.WaitDelay = waitDelay
+}
+
+// killGroup kills the process group led by cmd.
+func killGroup(cmd *exec.Cmd) {
+	syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+}
+
+// run executes cmd within the session limits. If limiter is not nil, the
+// process is also stopped as soon as it reports too much output. The process
+// runs in its own process group, which is killed once it's done.
+func run(cmd *exec.Cmd, session GocfSession, limiter outputLimiter) (Verdict, RunStats) {
+	stats := RunStats{ExitCode: -1}
+	var stderr *tailBuffer
+	if cmd.Stderr == nil {
+		stderr = &tailBuffer{limit: StderrLimit}
+		cmd.Stderr = stderr
+	}
+	setProcessGroup(cmd)
+	start := time.Now()
 	if err := cmd.Start(); err != nil {
-		return RTE
+		return RTE, stats
 	}
-	done := make(chan error)
+	defer killGroup(cmd)
+	done := make(chan error, 1)
 	go func() { done <- cmd.Wait() }()
-	select {
-	case err := <-done:
-		if err != nil {
-			return RTE
-		} else {
-			return OK
+	var timeout <-chan time.Time
+	if session.IdleTimeout() > 0 {
+		timeout = time.After(session.IdleTimeout())
+	}
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	result := OK
+	for {
+		select {
+		case err := <-done:
+			stats.WallTime = time.Since(start)
+			stats.CPUTime = cmd.ProcessState.UserTime() + cmd.ProcessState.SystemTime()
+			if mem := peakMemory(cmd.ProcessState); mem > stats.Memory {
+				stats.Memory = mem
+			}
+			stats.ExitCode = cmd.ProcessState.ExitCode()
+			if status, ok := cmd.ProcessState.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+				stats.Signal = signalName(status.Signal())
+			}
+			if stderr != nil {
+				stats.Stderr = stderr.String()
+			}
+			switch {
+			case securityViolation(cmd.ProcessState):
+				result = SV
+			case exceedsTimeLimit(session, stats.CPUTime):
+				result = TLE
+			case result != OK:
+				// killed by us, keep the reason
+			case limiter != nil && limiter.exceeded():
+				result = OLE
+			case exceedsMemLimit(session, stats.Memory):
+				result = MLE
+			case err != nil && !errors.Is(err, exec.ErrWaitDelay):
+				result = RTE
+			}
+			return result, stats
+		case <-ticker.C:
+			if result != OK {
+				continue
+			}
+			if mem := currentPeakMemory(cmd.Process.Pid); mem > stats.Memory {
+				stats.Memory = mem
+			}
+			if limiter != nil && limiter.exceeded() {
+				killGroup(cmd)
+				result = OLE
+			} else if exceedsMemLimit(session, stats.Memory) {
+				killGroup(cmd)
+				result = MLE
+			} else if exceedsTimeLimit(session, currentCPUTime(cmd.Process.Pid)) {
+				killGroup(cmd)
+				result = TLE
+			}
+		case <-timeout:
+			if result == OK {
+				killGroup(cmd)
+				result = ILE
+			}
 		}
-	case <-time.After(time.Duration(session.TimeLimit) * time.Millisecond):
-		cmd.Process.Kill()
-		return TLE
 	}
 }
 
-func check(config GocfConfig, session GocfSession, id int) int {
+func exceedsTimeLimit(session GocfSession, cpu time.Duration) bool {
+	return session.TimeLimit > 0 && cpu > time.Duration(session.TimeLimit)*time.Millisecond
+}
+
+func exceedsMemLimit(session GocfSession, mem int64) bool {
+	return session.MemLimit > 0 && mem > int64(session.MemLimit)
+}
+
+func check(config GocfConfig, session GocfSession, checker []string, id int) (Verdict, CheckOutcome) {
 	poolDir := config.SessionDir + "/" + TestPoolDir
 	inputFile := poolDir + "/" + strconv.Itoa(id) + ".in"
 	outputFile := poolDir + "/" + strconv.Itoa(id) + ".out"
 	answerFile := poolDir + "/" + strconv.Itoa(id) + ".ans"
+	return checkFiles(session, checker, poolDir, inputFile, outputFile, answerFile)
+}
+
+// checkFiles runs the session checker, given the command running it for
+// custom checkers. Custom checkers follow the testlib protocol: they receive
+// the input, output, answer and result files, report the verdict with their
+// exit code and leave a comment in the result file or in their standard error.
+func checkFiles(session GocfSession, checker []string, dir, inputFile, outputFile, answerFile string) (Verdict, CheckOutcome) {
 	if FileNotExist(answerFile) {
-		return OK
+		return UNCHECKED, CheckOutcome{}
 	}
 
-	if session.Checker == "*" {
-		if err := lcmp(outputFile, answerFile); err != nil {
-			return WA
+	if check, ok := BuiltinCheckers[session.Checker]; ok {
+		return runBuiltinChecker(check, outputFile, answerFile)
+	}
+
+	resultFile := outputFile + ".result"
+	os.Remove(resultFile)
+	args := append(append([]string{}, checker[1:]...), inputFile, outputFile, answerFile, resultFile)
+	cmd := exec.Command(checker[0], args...)
+	cmd.Dir = dir
+	stderr := &truncBuffer{limit: PrintLimit}
+	cmd.Stderr = stderr
+	if session.Sandbox {
+		if err := sandbox(cmd, dir); err != nil {
+			return FAIL, CheckOutcome{Message: err.Error()}
 		}
+	}
+	err := cmd.Run()
+	if securityViolation(cmd.ProcessState) {
+		return SV, CheckOutcome{Message: "checker killed by the sandbox"}
+	}
+	if cmd.ProcessState == nil || cmd.ProcessState.ExitCode() < 0 {
+		return FAIL, CheckOutcome{Message: err.Error()}
+	}
+	comment := stderr.String()
+	if FileExists(resultFile) {
+		comment = ReadHead(resultFile, PrintLimit)
+	}
+	return testlibVerdict(cmd.ProcessState.ExitCode(), comment)
+}
+
+// runDir returns the working directory of test #id, so that tests running
+// at the same time don't clash on the session input and output files.
+func runDir(config GocfConfig, id int) string {
+	return config.SessionDir + "/" + TestPoolDir + "/run" + strconv.Itoa(id)
+}
+
+// Programs holds the commands running the solution and the custom checker
+// of a session, once built.
+type Programs struct {
+	Solution   []string
+	Checker    []string // nil for built-in checkers
+	Interactor []string // nil if the task is not interactive
+}
+
+func TestOne(config GocfConfig, session GocfSession, programs Programs, id int) TestResult {
+	var result TestResult
+	if session.Interactor != "" {
+		result = TestInteractive(config, session, programs, id)
 	} else {
-		cmd := exec.Command(session.Checker, inputFile, outputFile, answerFile)
-		cmd.Dir = poolDir
-		if err := cmd.Run(); err != nil {
-			return WA
+		poolDir := config.SessionDir + "/" + TestPoolDir
+		inputFile := poolDir + "/" + strconv.Itoa(id) + ".in"
+		outputFile := poolDir + "/" + strconv.Itoa(id) + ".out"
+		result = TestResult{Id: id}
+		result.Verdict, result.Stats = execute(session, programs.Solution, runDir(config, id), inputFile, outputFile)
+		if result.Verdict == OK {
+			result.Verdict, result.Check = check(config, session, programs.Checker, id)
 		}
 	}
-
-	return OK
+	if result.Verdict == RTE {
+		result.Cause, result.Trace = crashCause(result.Stats)
+	}
+	return result
 }
 
-func TestOne(config GocfConfig, session GocfSession, id int) (int, time.Duration) {
-	poolDir := config.SessionDir + "/" + TestPoolDir
-	bin := poolDir + "/solution"
-	cmd := exec.Command(bin)
+// execute runs program inside dir, feeding it inputFile and saving what it
+// writes to outputFile, according to the session input and output specs.
+func execute(session GocfSession, program []string, dir, inputFile, outputFile string) (Verdict, RunStats) {
+	os.MkdirAll(dir, os.ModePerm)
+	defer os.RemoveAll(dir)
+	cmd := exec.Command(program[0], program[1:]...)
+	if session.Sandbox {
+		if err := sandbox(cmd, dir); err != nil {
+			return RTE, RunStats{ExitCode: -1}
+		}
+	}
 
-	cmd.Dir = poolDir
+	cmd.Dir = dir
 	if session.Input == "*" {
 		// redirect input file to process standard input
-		r, _ := os.Open(poolDir + "/" + strconv.Itoa(id) + ".in")
+		r, _ := os.Open(inputFile)
 		defer r.Close()
 		cmd.Stdin = r
 	} else {
 		// create symlink to expected input file
-		os.Link(poolDir+"/"+strconv.Itoa(id)+".in", poolDir+"/"+session.Input)
-		defer os.Remove(poolDir + "/" + session.Input)
+		os.Symlink(inputFile, dir+"/"+session.Input)
 	}
 
+	var limiter outputLimiter
 	if session.Output == "*" {
 		// redirect output
-		out, _ := os.Create(poolDir + "/" + strconv.Itoa(id) + ".out")
+		out, _ := os.Create(outputFile)
 		defer out.Close()
 		cmd.Stdout = out
+		if session.OutputLimit > 0 {
+			lw := &limitWriter{w: out, limit: int64(session.OutputLimit)}
+			cmd.Stdout = lw
+			limiter = lw
+		}
+	} else if session.OutputLimit > 0 {
+		limiter = fileLimiter{dir + "/" + session.Output, int64(session.OutputLimit)}
 	}
 
-	start := time.Now()
-	if err := run(cmd, session); err != OK {
-		elapsed := time.Since(start)
-		return err, elapsed
+	result, stats := run(cmd, session, limiter)
+	if result != OK {
+		return result, stats
 	}
-	elapsed := time.Since(start)
 
 	if session.Output != "*" {
-		os.Rename(poolDir+"/"+session.Output, poolDir+"/"+strconv.Itoa(id)+".out")
+		os.Rename(dir+"/"+session.Output, outputFile)
+	}
+	return result, stats
+}
+
+// runTests runs the given tests using at most opts.Jobs workers, opts.Repeat
+// times each. Results are returned in the same order as ids. With opts.FailFast, no more tests are
+// started after the first one not passing, and only the tests that actually
+// ran are returned.
+func runTests(config GocfConfig, session GocfSession, programs Programs, ids []int, opts TestOptions) []TestResult {
+	results := make([]TestResult, len(ids))
+	ran := make([]bool, len(ids))
+	var mu sync.Mutex
+	next, stop := 0, false
+	take := func() (int, bool) {
+		mu.Lock()
+		defer mu.Unlock()
+		if stop || next == len(ids) {
+			return 0, false
+		}
+		next++
+		return next - 1, true
+	}
+
+	var wg sync.WaitGroup
+	for w := 0; w < opts.Jobs; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i, ok := take(); ok; i, ok = take() {
+				if opts.Repeat > 1 {
+					runs := make([]TestResult, opts.Repeat)
+					for r := range runs {
+						runs[r] = TestOne(config, session, programs, ids[i])
+					}
+					results[i] = combineRuns(runs)
+				} else {
+					results[i] = TestOne(config, session, programs, ids[i])
+				}
+				ran[i] = true
+				if opts.FailFast && results[i].Verdict.Failed() {
+					mu.Lock()
+					stop = true
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	var done []TestResult
+	for i := range ids {
+		if ran[i] {
+			done = append(done, results[i])
+		}
+	}
+	return done
+}
+
+// sessionFilePath returns the path of a file given in the session, such as
+// a custom checker. Relative paths are taken from the session directory, so
+// that a source kept there is archived and restored along with the tests.
+func sessionFilePath(config GocfConfig, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(config.SessionDir, path)
+}
+
+// sessionProgram returns the command running a program given in the session,
+// building it first if it's a source file. What the program is for is told
+// by name, for the messages.
+func sessionProgram(config GocfConfig, name, path string) ([]string, error) {
+	fullPath := sessionFilePath(config, path)
+	if FileNotExist(fullPath) {
+		return nil, errors.New(name + " not found: " + path)
+	}
+	lang, err := LanguageByFile(fullPath)
+	if err != nil {
+		return []string{fullPath}, nil
+	}
+	program, _, out, err := lang.BuildCached(fullPath, nil, false)
+	if err != nil {
+		return nil, errors.New(name + " compilation error\n" + out)
+	}
+	return program, nil
+}
+
+// RequireSessionTools returns the commands running the custom checker and
+// the interactor of the session, building them first if they're source
+// files. It exits if they can't be found, or if the session asks for a
+// sandbox which can't be set up.
+func RequireSessionTools(config GocfConfig, session GocfSession) Programs {
+	programs, err := sessionTools(config, session)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
 	}
+	return programs
+}
 
-	return check(config, session, id), elapsed
+func sessionTools(config GocfConfig, session GocfSession) (Programs, error) {
+	var programs Programs
+	var err error
+	if !IsBuiltinChecker(session.Checker) {
+		if programs.Checker, err = sessionProgram(config, "Checker", session.Checker); err != nil {
+			return programs, err
+		}
+	}
+	if session.Interactor != "" {
+		if programs.Interactor, err = sessionProgram(config, "Interactor", session.Interactor); err != nil {
+			return programs, err
+		}
+	}
+	if session.Sandbox {
+		if err := CheckSandbox(); err != nil {
+			return programs, errors.New("Cannot set up the sandbox: " + err.Error())
+		}
+	}
+	return programs, nil
+}
+
+// selectTests returns the given test ids, sorted and without repetitions (as
+// ranges may overlap), or all of them if there are none.
+func selectTests(config GocfConfig, ids []int) ([]int, error) {
+	if len(ids) == 0 {
+		for id := 1; FileExists(inPath(config, id)); id++ {
+			ids = append(ids, id)
+		}
+	}
+	var selected []int
+	seen := make(map[int]bool)
+	for _, id := range ids {
+		if FileNotExist(inPath(config, id)) {
+			return nil, fmt.Errorf("No test with id %d", id)
+		}
+		if !seen[id] {
+			seen[id] = true
+			selected = append(selected, id)
+		}
+	}
+	sort.Ints(selected)
+	return selected, nil
 }
 
-func TestAll(config GocfConfig) {
+func TestAll(config GocfConfig, opts TestOptions) {
 	session := LoadCurrentSession(config)
+	ids, err := selectTests(config, opts.Tests)
+	if err == nil {
+		err = ValidateGroups(config, session)
+	}
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
 	fmt.Println("Removing test directory...")
 	CleanTestDir(config, session)
 	fmt.Println("Copying test files...")
 	PopulateTestDir(config, session)
 	fmt.Println("Compiling...")
-	Compile(config, session)
-	if session.Checker != "*" && FileNotExist(session.Checker) {
-		fmt.Println("Checker not found:", session.Checker)
+	solution := Compile(config, session, opts.Rebuild)
+	programs := RequireSessionTools(config, session)
+	programs.Solution = solution
+	fmt.Println("Running...")
+	results := runTests(config, session, programs, ids, opts)
+
+	switch opts.Format {
+	case FormatText:
+		PrintResults(config, session, results)
+	case FormatJSON:
+		err = WriteJSONReport(opts.Report, config, session, results)
+	case FormatJUnit:
+		err = WriteJUnitReport(opts.Report, config, session, results)
+	}
+	if err != nil {
+		fmt.Println("Cannot write the report:", err)
 		os.Exit(1)
 	}
-	fmt.Println("Running...")
-	var outcomes []int
-	var times []time.Duration
-	id := 1
-	for FileExists(inPath(config, id)) {
-		outcome, elapsed := TestOne(config, session, id)
-		outcomes = append(outcomes, outcome)
-		times = append(times, elapsed)
-		id++
+	for _, result := range results {
+		if result.Verdict.Failed() {
+			os.Exit(1)
+		}
 	}
-
-	PrintResults(config, session, outcomes, times)
 }
 
-func PrintResults(config GocfConfig, session GocfSession, outcomes []int, times []time.Duration) {
+func PrintResults(config GocfConfig, session GocfSession, results []TestResult) bool {
 	testDir := config.SessionDir + "/" + TestPoolDir
-	testCount := len(outcomes)
-	for id := 1; id <= testCount; id++ {
+	for _, result := range results {
+		id := result.Id
 		inputFile := testDir + "/" + strconv.Itoa(id) + ".in"
 		outputFile := testDir + "/" + strconv.Itoa(id) + ".out"
 		answerFile := testDir + "/" + strconv.Itoa(id) + ".ans"
@@ -254,43 +728,101 @@ func PrintResults(config GocfConfig, session GocfSession, outcomes []int, times
 		fmt.Printf("Test #%d:\n", id)
 
 		fmt.Println("Input:")
-		input, _ := ioutil.ReadFile(inputFile)
-		fmt.Println(string(input))
+		fmt.Println(ReadHead(inputFile, PrintLimit))
+
+		// on wrong answers, show where the output differs rather than both in full
+		diff := ""
+		if (result.Verdict == WA || result.Verdict == PE) && session.Interactor == "" && FileExists(answerFile) {
+			diff = Diff(outputFile, answerFile)
+		}
 
-		fmt.Println("Expected output:")
-		if FileExists(answerFile) {
-			answer, _ := ioutil.ReadFile(answerFile)
-			fmt.Println(string(answer))
+		if diff != "" {
+			fmt.Println("Difference:")
+			fmt.Println(diff)
 		} else {
-			fmt.Println("UNKNOWN\n")
+			fmt.Println("Expected output:")
+			if FileExists(answerFile) {
+				fmt.Println(ReadHead(answerFile, PrintLimit))
+			} else {
+				fmt.Print("UNKNOWN\n\n")
+			}
+		}
+
+		if result.Check.Message != "" {
+			fmt.Println("Checker comment:")
+			fmt.Println(result.Check.Message)
+			fmt.Println()
+		}
+
+		if result.Trace != "" {
+			fmt.Println("Panic:")
+			fmt.Println(result.Trace)
+			fmt.Println()
+		} else if result.Stats.Stderr != "" {
+			fmt.Println("Standard error:")
+			fmt.Println(tail(result.Stats.Stderr, PrintLimit))
+		}
+
+		if session.Interactor != "" {
+			fmt.Println("Interaction log:")
+			fmt.Println(ReadHead(logPath(testDir, id), PrintLimit))
+			continue
+		}
+		if diff != "" {
+			continue
 		}
 
 		fmt.Println("Execution output:")
 		if FileExists(outputFile) {
-			output, _ := ioutil.ReadFile(outputFile)
-			fmt.Println(string(output))
+			fmt.Println(ReadHead(outputFile, PrintLimit))
 		} else {
-			fmt.Println("\n")
+			fmt.Print("\n\n")
 		}
 	}
 
 	fmt.Println("==========================================================")
 	fmt.Println(" SUMMARY")
 	fmt.Println("==========================================================")
-	passed := 0
-	for i := 0; i < testCount; i++ {
-		if outcomes[i] == OK {
-			passed++
+	failed, unchecked := 0, 0
+	for _, result := range results {
+		if result.Verdict.Failed() {
+			failed++
+		} else if result.Verdict == UNCHECKED {
+			unchecked++
+		}
+		fmt.Printf("  Test #%d [cpu %.3fs, wall %.3fs, %s]: %s\n", result.Id, result.Stats.CPUTime.Seconds(),
+			result.Stats.WallTime.Seconds(), FormatMemory(result.Stats.Memory), result.Detail())
+		if result.Timing != nil {
+			fmt.Println("    " + result.Timing.Describe(session))
 		}
-		fmt.Printf("  Test #%d [%.3fs]: %s\n", i+1, times[i].Seconds(), ResultMsg(outcomes[i]))
+	}
+	if len(session.Groups) > 0 {
+		fmt.Println("----------------------------------------------------------")
+		fmt.Println(" GROUPS")
+		printGroupScores(ScoreGroups(session, results))
 	}
 	fmt.Println("----------------------------------------------------------")
-	if passed == testCount {
-		fmt.Println(" RESULT: All tests passed!")
-	} else {
+	if failed > 0 {
 		fmt.Println(" RESULT: Some tests are failing...")
+	} else if unchecked > 0 {
+		fmt.Printf(" RESULT: All checked tests passed, but %d have no answer (UNCHECKED)\n", unchecked)
+	} else {
+		fmt.Println(" RESULT: All tests passed!")
 	}
 	fmt.Println("==========================================================")
+	return failed == 0
+}
+
+// verdictDetail describes a verdict along with the points and the first line of the checker comment.
+func verdictDetail(verdict Verdict, check CheckOutcome) string {
+	msg := verdict.String()
+	if verdict == PC {
+		msg += fmt.Sprintf(" [%g points]", check.Points)
+	}
+	if verdict != OK && check.Message != "" {
+		msg += " (" + strings.SplitN(check.Message, "\n", 2)[0] + ")"
+	}
+	return msg
 }
 
 func ListTests(config GocfConfig, session GocfSession) {
@@ -300,6 +832,10 @@ func ListTests(config GocfConfig, session GocfSession) {
 		fmt.Println("-----------------------------------------------------------------------")
 		fmt.Println("INPUT #" + strconv.Itoa(id))
 		fmt.Println("-----------------------------------------------------------------------")
+		if FileExists(genPath(config, id)) {
+			b, _ := ioutil.ReadFile(genPath(config, id))
+			fmt.Print("(generated by: " + strings.TrimSpace(string(b)) + ")\n")
+		}
 		b, _ := ioutil.ReadFile(inPath(config, id))
 		fmt.Print(string(b))
 		if FileExists(ansPath(config, id)) {
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+const SandboxCommand string = "__sandbox__"
+
+var errNoSandbox = errors.New("the sandbox is only supported on Linux")
+
+func sandbox(cmd *exec.Cmd, dir string) error {
+	return errNoSandbox
+}
+
+func CheckSandbox() error {
+	return errNoSandbox
+}
+
+func RunSandbox(args []string) {
+	fmt.Fprintln(os.Stderr, errNoSandbox)
+	os.Exit(1)
+}
@@ -0,0 +1,333 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+	"unsafe"
+)
+
+// The sandbox works by running the program through gocf itself, started with
+// SandboxCommand in new user, mount and network namespaces. There, before
+// replacing itself with the program, gocf makes every mount read-only except
+// for the directory the program runs in, drops all capabilities and installs
+// a seccomp filter that kills the program if it tries to create processes or
+// network sockets. The network namespace has no interfaces at all.
+
+const SandboxCommand string = "__sandbox__"
+
+// the most threads a sandboxed program can have at once
+const SandboxMaxTasks = 256
+
+const (
+	prSetNoNewPrivs   = 38
+	prSetSeccomp      = 22
+	prCapbsetDrop     = 24
+	seccompModeFilter = 2
+	lastCap           = 63
+
+	seccompRetKillProcess = 0x80000000
+	seccompRetErrno       = 0x00050000
+	seccompRetAllow       = 0x7fff0000
+
+	sysClone3 = 435
+
+	rlimitNproc = 6 // RLIMIT_NPROC
+)
+
+// sandbox makes cmd run inside the sandbox, only allowed to write into dir.
+func sandbox(cmd *exec.Cmd, dir string) error {
+	return sandboxWith(cmd, dir, append([]string{"--", cmd.Path}, cmd.Args[1:]...))
+}
+
+func sandboxWith(cmd *exec.Cmd, dir string, args []string) error {
+	self, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	dir, err = filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	cmd.Path = self
+	cmd.Args = append([]string{self, SandboxCommand, dir}, args...)
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Cloneflags = syscall.CLONE_NEWUSER | syscall.CLONE_NEWNS | syscall.CLONE_NEWNET
+	// gocf must be root inside the namespace to set up the mounts
+	cmd.SysProcAttr.UidMappings = []syscall.SysProcIDMap{{ContainerID: 0, HostID: os.Getuid(), Size: 1}}
+	cmd.SysProcAttr.GidMappings = []syscall.SysProcIDMap{{ContainerID: 0, HostID: os.Getgid(), Size: 1}}
+	cmd.SysProcAttr.GidMappingsEnableSetgroups = false
+	return nil
+}
+
+// CheckSandbox tells whether the sandbox can be set up in this system.
+func CheckSandbox() error {
+	dir, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	cmd := &exec.Cmd{}
+	if err := sandboxWith(cmd, dir, []string{"-check"}); err != nil {
+		return err
+	}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%v %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// RunSandbox is the entry point of gocf inside the sandbox. It expects the
+// writable directory, followed by either "-check" or "--" and the command to
+// run. It never returns.
+func RunSandbox(args []string) {
+	if len(args) < 2 || (args[1] != "-check" && (args[1] != "--" || len(args) < 3)) {
+		fmt.Fprintln(os.Stderr, "sandbox: invalid arguments")
+		os.Exit(1)
+	}
+	// never touch the mounts of the caller's namespace
+	if !inSandboxNamespace() {
+		fmt.Fprintln(os.Stderr, "sandbox: not running in a dedicated user namespace")
+		os.Exit(1)
+	}
+	runtime.LockOSThread()
+	if err := setupSandbox(args[0]); err != nil {
+		fmt.Fprintln(os.Stderr, "sandbox:", err)
+		os.Exit(1)
+	}
+	if args[1] == "-check" {
+		os.Exit(0)
+	}
+	err := syscall.Exec(args[2], args[2:], os.Environ())
+	fmt.Fprintln(os.Stderr, "sandbox:", err)
+	os.Exit(1)
+}
+
+// inSandboxNamespace tells whether the process runs in a user namespace
+// created by sandbox, which maps a single user.
+func inSandboxNamespace() bool {
+	b, err := ioutil.ReadFile("/proc/self/uid_map")
+	if err != nil {
+		return false
+	}
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	fields := strings.Fields(lines[0])
+	return len(lines) == 1 && len(fields) == 3 && fields[0] == "0" && fields[2] == "1"
+}
+
+func setupSandbox(dir string) error {
+	if err := syscall.Mount("", "/", "", syscall.MS_REC|syscall.MS_PRIVATE, ""); err != nil {
+		return errors.New("cannot make mounts private: " + err.Error())
+	}
+	if err := syscall.Mount(dir, dir, "", syscall.MS_BIND|syscall.MS_REC, ""); err != nil {
+		return errors.New("cannot bind " + dir + ": " + err.Error())
+	}
+	if err := remountReadOnly(dir); err != nil {
+		return err
+	}
+	// the working directory must point to the new mount
+	if err := syscall.Chdir(dir); err != nil {
+		return err
+	}
+	for c := 0; c <= lastCap; c++ {
+		if err := prctl(prCapbsetDrop, uintptr(c), 0); err != nil && err != syscall.EINVAL {
+			return errors.New("cannot drop capabilities: " + err.Error())
+		}
+	}
+	if err := prctl(prSetNoNewPrivs, 1, 0); err != nil {
+		return errors.New("cannot set no_new_privs: " + err.Error())
+	}
+	if err := limitTasks(); err != nil {
+		return err
+	}
+	return installSeccomp()
+}
+
+// limitTasks caps the number of threads of the sandbox, which can't create
+// processes but could still exhaust the process ids with threads. The limit
+// only counts the tasks of the sandbox since Linux 5.14, before which it
+// would count every task of the user, so it's not set on older kernels.
+func limitTasks() error {
+	if !kernelAtLeast(5, 14) {
+		return nil
+	}
+	limit := &syscall.Rlimit{Cur: SandboxMaxTasks, Max: SandboxMaxTasks}
+	if err := syscall.Setrlimit(rlimitNproc, limit); err != nil {
+		return errors.New("cannot limit the number of tasks: " + err.Error())
+	}
+	return nil
+}
+
+func kernelAtLeast(major, minor int) bool {
+	var uts syscall.Utsname
+	if err := syscall.Uname(&uts); err != nil {
+		return false
+	}
+	var release []byte
+	for _, c := range uts.Release {
+		if c == 0 {
+			break
+		}
+		release = append(release, byte(c))
+	}
+	var m, n int
+	fmt.Sscanf(string(release), "%d.%d", &m, &n)
+	return m > major || (m == major && n >= minor)
+}
+
+func prctl(option, arg2, arg3 uintptr) error {
+	if _, _, errno := syscall.RawSyscall(syscall.SYS_PRCTL, option, arg2, arg3); errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+var mountFlags = map[string]uintptr{
+	"nosuid":      syscall.MS_NOSUID,
+	"nodev":       syscall.MS_NODEV,
+	"noexec":      syscall.MS_NOEXEC,
+	"noatime":     syscall.MS_NOATIME,
+	"nodiratime":  syscall.MS_NODIRATIME,
+	"relatime":    syscall.MS_RELATIME,
+	"strictatime": syscall.MS_STRICTATIME,
+}
+
+// remountReadOnly makes every mount read-only, except for the one at dir.
+// The per-mount flags are kept, since they can't be cleared from a user
+// namespace. It fails if any writable mount can't be made read-only.
+func remountReadOnly(dir string) error {
+	f, err := os.Open("/proc/self/mountinfo")
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	var writable []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 6 {
+			continue
+		}
+		target := unescapeMountPath(fields[4])
+		if target == dir {
+			continue
+		}
+		flags := uintptr(syscall.MS_REMOUNT | syscall.MS_BIND | syscall.MS_RDONLY)
+		readOnly := false
+		for _, opt := range strings.Split(fields[5], ",") {
+			flags |= mountFlags[opt]
+			readOnly = readOnly || opt == "ro"
+		}
+		if err := syscall.Mount("", target, "", flags, ""); err != nil && !readOnly {
+			writable = append(writable, target+" ("+err.Error()+")")
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if len(writable) > 0 {
+		return errors.New("cannot make read-only: " + strings.Join(writable, ", "))
+	}
+	return nil
+}
+
+// unescapeMountPath decodes the octal escapes (e.g. \040 for a space) used in /proc/self/mountinfo.
+func unescapeMountPath(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+3 < len(s) {
+			if c, err := strconv.ParseUint(s[i+1:i+4], 8, 8); err == nil {
+				b.WriteByte(byte(c))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// installSeccomp installs a filter which kills the process if it tries to
+// create a process (but not a thread), a socket other than a Unix socket, or
+// to change its namespaces and mounts.
+func installSeccomp() error {
+	if auditArch == 0 {
+		return errors.New("seccomp is not supported on " + runtime.GOARCH)
+	}
+	const (
+		ld   = syscall.BPF_LD | syscall.BPF_W | syscall.BPF_ABS
+		jeq  = syscall.BPF_JMP | syscall.BPF_JEQ | syscall.BPF_K
+		jge  = syscall.BPF_JMP | syscall.BPF_JGE | syscall.BPF_K
+		jset = syscall.BPF_JMP | syscall.BPF_JSET | syscall.BPF_K
+		ret  = syscall.BPF_RET | syscall.BPF_K
+
+		// offsets in struct seccomp_data
+		nrOffset   = 0
+		archOffset = 4
+		arg0Offset = 16
+	)
+	stmt := func(code uint16, k uint32) syscall.SockFilter {
+		return syscall.SockFilter{Code: code, K: k}
+	}
+	jump := func(code uint16, k uint32, jt, jf uint8) syscall.SockFilter {
+		return syscall.SockFilter{Code: code, Jt: jt, Jf: jf, K: k}
+	}
+
+	filter := []syscall.SockFilter{
+		stmt(ld, archOffset),
+		jump(jeq, auditArch, 1, 0),
+		stmt(ret, seccompRetKillProcess),
+		stmt(ld, nrOffset),
+	}
+	if x32SyscallBit != 0 {
+		// the rules below only match the native numbers
+		filter = append(filter,
+			jump(jge, x32SyscallBit, 0, 1),
+			stmt(ret, seccompRetKillProcess),
+		)
+	}
+	// clone is only allowed for threads
+	filter = append(filter,
+		jump(jeq, syscall.SYS_CLONE, 0, 4),
+		stmt(ld, arg0Offset),
+		jump(jset, syscall.CLONE_THREAD, 0, 1),
+		stmt(ret, seccompRetAllow),
+		stmt(ret, seccompRetKillProcess),
+	)
+	// clone3 arguments can't be inspected, but libc falls back to clone
+	filter = append(filter,
+		jump(jeq, sysClone3, 0, 1),
+		stmt(ret, seccompRetErrno|uint32(syscall.ENOSYS)),
+	)
+	filter = append(filter,
+		jump(jeq, sysSocket, 0, 4),
+		stmt(ld, arg0Offset),
+		jump(jeq, syscall.AF_UNIX, 0, 1),
+		stmt(ret, seccompRetAllow),
+		stmt(ret, seccompRetKillProcess),
+	)
+	forbidden := append(forkSyscalls, syscall.SYS_MOUNT, syscall.SYS_UMOUNT2, syscall.SYS_PIVOT_ROOT,
+		syscall.SYS_CHROOT, syscall.SYS_UNSHARE, sysSetns, syscall.SYS_PTRACE)
+	for _, nr := range forbidden {
+		filter = append(filter,
+			jump(jeq, uint32(nr), 0, 1),
+			stmt(ret, seccompRetKillProcess),
+		)
+	}
+	filter = append(filter, stmt(ret, seccompRetAllow))
+
+	prog := syscall.SockFprog{Len: uint16(len(filter)), Filter: &filter[0]}
+	if err := prctl(prSetSeccomp, seccompModeFilter, uintptr(unsafe.Pointer(&prog))); err != nil {
+		return errors.New("cannot install seccomp filter: " + err.Error())
+	}
+	return nil
+}
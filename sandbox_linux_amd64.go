@@ -0,0 +1,14 @@
+package main
+
+import "syscall"
+
+const (
+	auditArch = 0xc000003e // AUDIT_ARCH_X86_64
+	sysSocket = syscall.SYS_SOCKET
+	sysSetns  = 308
+
+	// set in the numbers of x32 system calls, which pass as x86-64 ones
+	x32SyscallBit = 0x40000000
+)
+
+var forkSyscalls = []uintptr{syscall.SYS_FORK, syscall.SYS_VFORK}
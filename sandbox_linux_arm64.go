@@ -0,0 +1,14 @@
+package main
+
+import "syscall"
+
+const (
+	auditArch = 0xc00000b7 // AUDIT_ARCH_AARCH64
+	sysSocket = syscall.SYS_SOCKET
+	sysSetns  = 268
+
+	x32SyscallBit = 0
+)
+
+// processes can only be created with clone on arm64
+var forkSyscalls = []uintptr{}
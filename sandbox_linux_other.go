@@ -0,0 +1,14 @@
+//go:build linux && !amd64 && !arm64
+
+package main
+
+// the seccomp filter is only available on amd64 and arm64
+const (
+	auditArch = 0
+	sysSocket = 0
+	sysSetns  = 0
+
+	x32SyscallBit = 0
+)
+
+var forkSyscalls = []uintptr{}
@@ -7,16 +7,25 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"time"
 )
 
 type GocfSession struct {
-	Contest   string
-	Task      string
-	Input     string
-	Output    string
-	TimeLimit int // milliseconds
-	MemLimit  int // bytes
-	Checker   string
+	Contest      string
+	Task         string
+	Input        string
+	Output       string
+	TimeLimit    int // milliseconds, of CPU time (0 means no limit)
+	MemLimit     int // bytes (0 means no limit)
+	Checker      string
+	IdleLimit    int         // milliseconds, of wall-clock time (0 means 3 times TimeLimit)
+	Interactor   string      // interactor executable, empty if the task is not interactive
+	OutputLimit  int         // bytes (0 means no limit)
+	Sandbox      bool        // run the solution and checker isolated (Linux only)
+	Language     string      // language profile name, "*" to choose it from the work file extension
+	CompileFlags []string    // extra compiler flags for the work file
+	Groups       []TestGroup // subtasks, empty if the task is not scored by groups
+	Validator    string      // input validator executable or source, empty if none
 }
 
 const SessionFileName string = "/session.json"
@@ -27,20 +36,34 @@ const DefaultOutput string = "*"
 const DefaultTimeLimit int = 1000
 const DefaultMemLimit int = 64 * (1 << 20)
 const DefaultChecker string = "*"
+const DefaultOutputLimit int = 64 * (1 << 20)
+const DefaultLanguage string = "*"
 const SolutionFile string = "__solution__"
 
 func DefaultSession() GocfSession {
 	return GocfSession{
-		Contest:   "practice",
-		Task:      "task",
-		Input:     "*", // stdin
-		Output:    "*", // stdout
-		TimeLimit: 1000,
-		MemLimit:  64 * (1 << 20),
-		Checker:   "*", // default checker
+		Contest:     "practice",
+		Task:        "task",
+		Input:       "*", // stdin
+		Output:      "*", // stdout
+		TimeLimit:   1000,
+		MemLimit:    64 * (1 << 20),
+		Checker:     "*", // default checker
+		OutputLimit: DefaultOutputLimit,
+		Language:    "*", // by work file extension
 	}
 }
 
+// IdleTimeout returns how long a solution may run, in wall-clock time,
+// before it's killed. This catches solutions blocked or sleeping, which
+// don't consume CPU time.
+func (session GocfSession) IdleTimeout() time.Duration {
+	if session.IdleLimit > 0 {
+		return time.Duration(session.IdleLimit) * time.Millisecond
+	}
+	return 3 * time.Duration(session.TimeLimit) * time.Millisecond
+}
+
 func (session GocfSession) String() string {
 	return "Session description:\n" +
 		"  Contest:    " + session.Contest + "\n" +
@@ -48,8 +71,36 @@ func (session GocfSession) String() string {
 		"  Input:      " + session.Input + "\n" +
 		"  Output:     " + session.Output + "\n" +
 		"  Time limit: " + strconv.Itoa(session.TimeLimit) + " [ms]\n" +
+		"  Idle limit: " + strconv.Itoa(int(session.IdleTimeout()/time.Millisecond)) + " [ms]\n" +
 		"  Mem limit:  " + strconv.Itoa(session.MemLimit/(1<<20)) + " [MiB]\n" +
-		"  Checker:    " + session.Checker + "\n"
+		"  Out limit:  " + strconv.Itoa(session.OutputLimit/(1<<20)) + " [MiB]\n" +
+		"  Checker:    " + session.Checker + "\n" +
+		"  Language:   " + session.Language + "\n" +
+		interactorString(session) +
+		validatorString(session) +
+		sandboxString(session) +
+		groupsString(session)
+}
+
+func sandboxString(session GocfSession) string {
+	if !session.Sandbox {
+		return ""
+	}
+	return "  Sandbox:    yes\n"
+}
+
+func validatorString(session GocfSession) string {
+	if session.Validator == "" {
+		return ""
+	}
+	return "  Validator:  " + session.Validator + "\n"
+}
+
+func interactorString(session GocfSession) string {
+	if session.Interactor == "" {
+		return ""
+	}
+	return "  Interactor: " + session.Interactor + "\n"
 }
 
 func (session GocfSession) Save(config GocfConfig) {
@@ -92,11 +143,11 @@ func (session GocfSession) Archive(config GocfConfig, overwrite bool) {
 
 func LoadCurrentSession(config GocfConfig) GocfSession {
 	sessionFile := config.SessionDir + SessionFileName
-	var session GocfSession
+	session := DefaultSession()
 	if FileNotExist(sessionFile) {
-		session = DefaultSession()
 		session.Save(config)
 	} else {
+		// properties missing in older session files keep their default value
 		contents, _ := ioutil.ReadFile(sessionFile)
 		json.Unmarshal(contents, &session)
 	}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+const StressDir string = "stress"
+
+// prepareProgram returns the command running a stress testing program,
+// building it first if it's a source file of a supported language.
+func prepareProgram(path string) ([]string, error) {
+	if FileNotExist(path) {
+		return nil, fmt.Errorf("program not found: %s", path)
+	}
+	lang, err := LanguageByFile(path)
+	if err != nil {
+		// not a source file, run it as is
+		bin, err := filepath.Abs(path)
+		return []string{bin}, err
+	}
+	program, _, out, err := lang.BuildCached(path, nil, false)
+	if err != nil {
+		return nil, fmt.Errorf("cannot compile %s:\n%s", path, out)
+	}
+	return program, nil
+}
+
+// Stress runs the work file against a brute-force solution on inputs made by
+// a generator, which receives the seed as its only argument. The first input
+// on which they disagree is added as a new test, with the output of the
+// brute-force solution as the answer.
+func Stress(config GocfConfig, gen, brute string, opts StressOptions) {
+	session := LoadCurrentSession(config)
+	if session.Interactor != "" {
+		fmt.Println("Stress testing is not supported for interactive tasks")
+		os.Exit(1)
+	}
+	checker := RequireSessionTools(config, session).Checker
+	var validator []string
+	if session.Validator != "" {
+		validator = RequireValidator(config, session)
+	}
+
+	fmt.Println("Removing test directory...")
+	CleanTestDir(config, session)
+	dir := config.SessionDir + "/" + TestPoolDir + "/" + StressDir
+	os.MkdirAll(dir, os.ModePerm)
+	fmt.Println("Compiling...")
+	solution := Compile(config, session, false)
+	genProgram, err := prepareProgram(gen)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	bruteProgram, err := prepareProgram(brute)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	// the brute-force solution is expected to be slow, so it runs without limits
+	bruteSession := session
+	bruteSession.TimeLimit, bruteSession.MemLimit, bruteSession.IdleLimit = 0, 0, 0
+
+	inputFile := dir + "/stress.in"
+	outputFile := dir + "/stress.out"
+	answerFile := dir + "/stress.ans"
+	start := time.Now()
+	fmt.Println("Running...")
+	for seed := 1; seed <= opts.Iterations; seed++ {
+		if opts.Duration > 0 && time.Since(start) > opts.Duration {
+			fmt.Println()
+			fmt.Println("Time budget exhausted after", seed-1, "iterations, no counterexample found")
+			return
+		}
+		fmt.Printf("\rIteration #%d", seed)
+
+		genArgs := append(append([]string{}, genProgram[1:]...), strconv.Itoa(seed))
+		input, err := exec.Command(genProgram[0], genArgs...).Output()
+		if err != nil {
+			fmt.Println()
+			fmt.Println("Generator failed with seed", seed, ":", err)
+			os.Exit(1)
+		}
+		if validator != nil {
+			if err := validateInput(validator, input); err != nil {
+				fmt.Println()
+				fmt.Println("Generator made an invalid input with seed", seed, ":", err)
+				os.Exit(1)
+			}
+		}
+		ioutil.WriteFile(inputFile, input, os.ModePerm)
+
+		if result, _ := execute(bruteSession, bruteProgram, dir+"/run-brute", inputFile, answerFile); result != OK {
+			fmt.Println()
+			fmt.Println("Brute-force solution failed with seed", seed, ":", result)
+			os.Exit(1)
+		}
+
+		result, _ := execute(session, solution, dir+"/run-solution", inputFile, outputFile)
+		var outcome CheckOutcome
+		if result == OK {
+			result, outcome = checkFiles(session, checker, dir, inputFile, outputFile, answerFile)
+		}
+		if result != OK {
+			answer, _ := ioutil.ReadFile(answerFile)
+			id := AddTest(config, input, answer)
+			fmt.Println()
+			fmt.Printf("Counterexample found with seed %d: %s\n", seed, verdictDetail(result, outcome))
+			fmt.Println("Added test #", id)
+			os.Exit(1)
+		}
+	}
+	fmt.Println()
+	fmt.Println("No counterexample found after", opts.Iterations, "iterations")
+}
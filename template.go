@@ -64,3 +64,133 @@ func Println(s string) {
 }
 `
 }
+
+func cppIOSetup(session GocfSession) string {
+	if session.Input == "*" {
+		return ""
+	}
+	return `	freopen("` + session.Input + `", "r", stdin);
+	freopen("` + session.Output + `", "w", stdout);
+`
+}
+
+func CppTemplate(session GocfSession) string {
+	return `#include <bits/stdc++.h>
+
+using namespace std;
+
+int main() {
+` + cppIOSetup(session) + `	ios::sync_with_stdio(false);
+	cin.tie(nullptr);
+
+	// TODO your code here
+
+	return 0;
+}
+`
+}
+
+func pythonIOSetup(session GocfSession) string {
+	if session.Input == "*" {
+		return ""
+	}
+	return `sys.stdin = open("` + session.Input + `")
+sys.stdout = open("` + session.Output + `", "w")
+`
+}
+
+func PythonTemplate(session GocfSession) string {
+	return `import sys
+
+` + pythonIOSetup(session) + `input = sys.stdin.readline
+
+
+def main():
+    # TODO your code here
+    pass
+
+
+main()
+`
+}
+
+func javaIOSetup(session GocfSession) string {
+	if session.Input == "*" {
+		return `		reader = new BufferedReader(new InputStreamReader(System.in));
+		writer = new PrintWriter(new BufferedWriter(new OutputStreamWriter(System.out)));`
+	}
+	return `		reader = new BufferedReader(new FileReader("` + session.Input + `"));
+		writer = new PrintWriter(new BufferedWriter(new FileWriter("` + session.Output + `")));`
+}
+
+// JavaTemplate declares the Main class, which is the one run by the java profile.
+func JavaTemplate(session GocfSession) string {
+	return `import java.io.*;
+import java.util.*;
+
+class Main {
+	public static void main(String[] args) throws IOException {
+` + javaIOSetup(session) + `
+
+		// TODO your code here
+
+		writer.flush();
+	}
+
+	/******************/
+	/* IO boilerplate */
+	/******************/
+
+	static BufferedReader reader;
+	static PrintWriter writer;
+	static StringTokenizer tokenizer = new StringTokenizer("");
+
+	static String next() throws IOException {
+		while (!tokenizer.hasMoreTokens()) {
+			tokenizer = new StringTokenizer(reader.readLine());
+		}
+		return tokenizer.nextToken();
+	}
+
+	static int nextInt() throws IOException {
+		return Integer.parseInt(next());
+	}
+
+	static long nextLong() throws IOException {
+		return Long.parseLong(next());
+	}
+
+	static double nextDouble() throws IOException {
+		return Double.parseDouble(next());
+	}
+}
+`
+}
+
+func rustIOSetup(session GocfSession) string {
+	if session.Input == "*" {
+		return `    let mut input = String::new();
+    io::stdin().read_to_string(&mut input).unwrap();
+    let mut writer = BufWriter::new(io::stdout().lock());`
+	}
+	return `    let input = fs::read_to_string("` + session.Input + `").unwrap();
+    let mut writer = BufWriter::new(fs::File::create("` + session.Output + `").unwrap());`
+}
+
+func RustTemplate(session GocfSession) string {
+	return `#[allow(unused_imports)]
+use std::fs;
+#[allow(unused_imports)]
+use std::io::{self, BufWriter, Read, Write};
+
+fn main() {
+` + rustIOSetup(session) + `
+    let mut tokens = input.split_ascii_whitespace();
+    let mut next = || tokens.next().unwrap();
+
+    // TODO your code here
+
+    writer.flush().unwrap();
+}
+`
+}
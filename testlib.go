@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Exit codes of checkers and interactors written with testlib.
+// @see https://github.com/MikeMirzayanov/testlib/blob/master/testlib.h
+const (
+	testlibOK            = 0
+	testlibWA            = 1
+	testlibPE            = 2
+	testlibFail          = 3
+	testlibDirt          = 4
+	testlibPoints        = 7
+	testlibUnexpectedEOF = 8
+	// Exit code 50+k means partial credit k, in percent. testlib only adds 50
+	// when built with TESTSYS (or PC_BASE_EXIT_CODE set to 50); otherwise _pc(k)
+	// exits with k, which can't be told apart from the other exit codes.
+	testlibPartialBase = 50
+)
+
+// CheckOutcome is what a checker (or interactor) reports besides the verdict.
+type CheckOutcome struct {
+	Message string
+	Points  float64 // the fraction of the points of the test, only meaningful for PC
+}
+
+// partialCredit returns the PC verdict for the given fraction of the points,
+// which must be between 0 and 1.
+func partialCredit(points float64, outcome CheckOutcome) (Verdict, CheckOutcome) {
+	if points < 0 || points > 1 {
+		outcome.Message = fmt.Sprintf("partial credit out of range [0, 1]: %g (%s)", points, outcome.Message)
+		return FAIL, outcome
+	}
+	outcome.Points = points
+	return PC, outcome
+}
+
+// testlibVerdict maps the exit code of a testlib checker or interactor and
+// its comment to a verdict.
+func testlibVerdict(code int, comment string) (Verdict, CheckOutcome) {
+	outcome := CheckOutcome{Message: strings.TrimSpace(comment)}
+	switch {
+	case code == testlibOK:
+		return OK, outcome
+	case code == testlibWA:
+		return WA, outcome
+	case code == testlibPE || code == testlibDirt || code == testlibUnexpectedEOF:
+		return PE, outcome
+	case code == testlibPoints:
+		// the comment is "points <value> <message>", the prefix may be missing
+		fields := strings.Fields(strings.TrimPrefix(outcome.Message, "points "))
+		if len(fields) > 0 {
+			if points, err := strconv.ParseFloat(fields[0], 64); err == nil {
+				outcome.Message = strings.Join(fields[1:], " ")
+				return partialCredit(points, outcome)
+			}
+		}
+		return FAIL, outcome
+	case code >= testlibPartialBase && code <= testlibPartialBase+200:
+		return partialCredit(float64(code-testlibPartialBase)/100, outcome)
+	default:
+		return FAIL, outcome
+	}
+}
@@ -0,0 +1,38 @@
+package main
+
+import "testing"
+
+func TestTestlibVerdict(t *testing.T) {
+	tests := []struct {
+		code    int
+		comment string
+		verdict Verdict
+		message string
+		points  float64
+	}{
+		{0, "ok 3 numbers\n", OK, "ok 3 numbers", 0},
+		{1, "wrong answer 1st numbers differ", WA, "wrong answer 1st numbers differ", 0},
+		{2, "wrong output format", PE, "wrong output format", 0},
+		{4, "extra information in the output file", PE, "extra information in the output file", 0},
+		{8, "unexpected eof", PE, "unexpected eof", 0},
+		{3, "fail answer is not an integer", FAIL, "fail answer is not an integer", 0},
+		{5, "", FAIL, "", 0},
+		{7, "points 0.5 half of the pairs", PC, "half of the pairs", 0.5},
+		{7, "0.25", PC, "", 0.25},
+		{7, "points 1.5 too much", FAIL, "partial credit out of range [0, 1]: 1.5 (too much)", 0},
+		{7, "points many", FAIL, "points many", 0},
+		{7, "", FAIL, "", 0},
+		{50, "", PC, "", 0},
+		{90, "partially correct", PC, "partially correct", 0.4},
+		{150, "", PC, "", 1},
+		{200, "", FAIL, "partial credit out of range [0, 1]: 1.5 ()", 0},
+		{251, "", FAIL, "", 0},
+	}
+	for _, test := range tests {
+		verdict, outcome := testlibVerdict(test.code, test.comment)
+		if verdict != test.verdict || outcome.Message != test.message || outcome.Points != test.points {
+			t.Errorf("testlibVerdict(%d, %q) = %v, %+v; want %v, {Message:%s Points:%g}",
+				test.code, test.comment, verdict, outcome, test.verdict, test.message, test.points)
+		}
+	}
+}
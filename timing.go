@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Timing summarizes the CPU time of a test run several times.
+type Timing struct {
+	Runs     int
+	Min      time.Duration
+	Median   time.Duration
+	Max      time.Duration
+	Unstable bool // the runs disagree on the verdict
+}
+
+// combineRuns merges the results of running a test several times. The first
+// run not passing is reported, if any, so that a failure is never hidden by
+// luckier runs.
+func combineRuns(runs []TestResult) TestResult {
+	result := runs[len(runs)-1]
+	for _, run := range runs {
+		if run.Verdict.Failed() {
+			result = run
+			break
+		}
+	}
+	times := make([]time.Duration, len(runs))
+	timing := &Timing{Runs: len(runs)}
+	for i, run := range runs {
+		times[i] = run.Stats.CPUTime
+		if run.Verdict != runs[0].Verdict {
+			timing.Unstable = true
+		}
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i] < times[j] })
+	timing.Min = times[0]
+	timing.Max = times[len(times)-1]
+	if len(times)%2 == 1 {
+		timing.Median = times[len(times)/2]
+	} else {
+		timing.Median = (times[len(times)/2-1] + times[len(times)/2]) / 2
+	}
+	result.Timing = timing
+	return result
+}
+
+// Margin returns how much CPU time the slowest run had left before the time
+// limit, which is negative if it went over. It's 0 if there's no limit.
+func (timing Timing) Margin(session GocfSession) time.Duration {
+	if session.TimeLimit <= 0 {
+		return 0
+	}
+	return time.Duration(session.TimeLimit)*time.Millisecond - timing.Max
+}
+
+func (timing Timing) Describe(session GocfSession) string {
+	desc := fmt.Sprintf("cpu min %.3fs, median %.3fs, max %.3fs over %d runs", timing.Min.Seconds(),
+		timing.Median.Seconds(), timing.Max.Seconds(), timing.Runs)
+	if session.TimeLimit > 0 {
+		margin := timing.Margin(session)
+		desc += fmt.Sprintf(", margin %.3fs (%.0f%% of the limit)", margin.Seconds(),
+			100*margin.Seconds()/(time.Duration(session.TimeLimit)*time.Millisecond).Seconds())
+	}
+	if timing.Unstable {
+		desc += ", UNSTABLE"
+	}
+	return desc
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 )
 
@@ -61,3 +62,29 @@ func ReadDefault(msg, def string) string {
 	}
 	return ret
 }
+
+// ReadHead returns at most the first n bytes of a file, noting if there is more.
+func ReadHead(filename string, n int64) string {
+	f, err := os.Open(filename)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	b, _ := ioutil.ReadAll(io.LimitReader(f, n))
+	if info, err := f.Stat(); err == nil && info.Size() > n {
+		return string(b) + fmt.Sprintf("\n... (truncated, %d bytes in total)", info.Size())
+	}
+	return string(b)
+}
+
+// tail returns the last n bytes of s.
+func tail(s string, n int) string {
+	if len(s) > n {
+		return "... (truncated)\n" + s[len(s)-n:]
+	}
+	return s
+}
+
+func FormatMemory(bytes int64) string {
+	return fmt.Sprintf("%.1fMiB", float64(bytes)/(1<<20))
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// RequireValidator returns the command running the session validator,
+// building it first if it's a source file. It exits if it can't be found.
+func RequireValidator(config GocfConfig, session GocfSession) []string {
+	validator, err := sessionProgram(config, "Validator", session.Validator)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	return validator
+}
+
+// validateInput runs the validator on a test input, given on its standard
+// input. Like testlib validators, it accepts the input by exiting with 0, and
+// tells what's wrong in its standard error otherwise.
+func validateInput(validator []string, input []byte) error {
+	cmd := exec.Command(validator[0], validator[1:]...)
+	cmd.Stdin = bytes.NewReader(input)
+	stderr := &truncBuffer{limit: PrintLimit}
+	cmd.Stderr = stderr
+	err := cmd.Run()
+	if err == nil {
+		return nil
+	}
+	if msg := strings.TrimSpace(stderr.String()); msg != "" {
+		return errors.New(msg)
+	}
+	return err
+}
+
+// ValidateTests checks the input of every test with the session validator,
+// and exits with a non-zero status if any of them is invalid.
+func ValidateTests(config GocfConfig) {
+	session := LoadCurrentSession(config)
+	if session.Validator == "" {
+		fmt.Println("The session has no validator")
+		os.Exit(1)
+	}
+	validator := RequireValidator(config, session)
+	invalid := 0
+	for id := 1; FileExists(inPath(config, id)); id++ {
+		input, _ := ioutil.ReadFile(inPath(config, id))
+		if err := validateInput(validator, input); err != nil {
+			fmt.Printf("  Test #%d: INVALID (%s)\n", id, strings.SplitN(err.Error(), "\n", 2)[0])
+			invalid++
+		} else {
+			fmt.Printf("  Test #%d: OK\n", id)
+		}
+	}
+	if invalid > 0 {
+		fmt.Println(invalid, "invalid tests")
+		os.Exit(1)
+	}
+	fmt.Println("All tests are valid")
+}
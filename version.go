@@ -0,0 +1,8 @@
+//go:build !go1.20
+// +build !go1.20
+
+package main
+
+// gocf needs Go 1.20 or later, for exec.Cmd.WaitDelay. This file is only built
+// by older versions, to make them fail with this message.
+var _ = gocf_requires_Go_1_20_or_later
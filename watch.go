@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// how often files are checked for changes when notifications are not available
+const watchPollInterval = 500 * time.Millisecond
+
+// how long to wait for more changes after a notification, as editors often
+// save files in several steps
+const watchSettleDelay = 100 * time.Millisecond
+
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// watchedFiles returns the state of the work file and the files of the
+// session directory (tests, session file, checker sources), but not the test
+// pool, which gocf writes itself.
+func watchedFiles(config GocfConfig) map[string]fileState {
+	files := make(map[string]fileState)
+	infos, _ := ioutil.ReadDir(config.SessionDir)
+	for _, info := range infos {
+		if !info.IsDir() {
+			files[filepath.Join(config.SessionDir, info.Name())] = fileState{info.ModTime(), info.Size()}
+		}
+	}
+	if info, err := os.Stat(config.WorkFile); err == nil {
+		files[config.WorkFile] = fileState{info.ModTime(), info.Size()}
+	}
+	return files
+}
+
+func sameFiles(a, b map[string]fileState) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for path, state := range a {
+		if other, ok := b[path]; !ok || other != state {
+			return false
+		}
+	}
+	return true
+}
+
+// watchRun runs the tests once, like TestAll, but only prints a summary of
+// the failing tests, and never exits.
+func watchRun(config GocfConfig, opts TestOptions) {
+	fmt.Println("==========================================================")
+	fmt.Println(time.Now().Format("15:04:05"), "Running tests...")
+	session := LoadCurrentSession(config)
+	ids, err := selectTests(config, opts.Tests)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	CleanTestDir(config, session)
+	PopulateTestDir(config, session)
+	solution, _, out, err := buildSolution(config, session, opts.Rebuild)
+	if err != nil {
+		fmt.Println("Compilation error")
+		fmt.Println(out)
+		return
+	}
+	programs, err := sessionTools(config, session)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	programs.Solution = solution
+
+	results := runTests(config, session, programs, ids, opts)
+	passed, unchecked := 0, 0
+	for _, result := range results {
+		if result.Verdict == OK {
+			passed++
+			continue
+		}
+		if result.Verdict == UNCHECKED {
+			unchecked++
+			continue
+		}
+		fmt.Printf("  Test #%d [cpu %.3fs, %s]: %s\n", result.Id, result.Stats.CPUTime.Seconds(),
+			FormatMemory(result.Stats.Memory), result.Detail())
+	}
+	if len(session.Groups) > 0 && ValidateGroups(config, session) == nil {
+		printGroupScores(ScoreGroups(session, results))
+	}
+	if passed == len(ids) {
+		fmt.Printf(" All %d tests passed!\n", passed)
+	} else {
+		fmt.Printf(" %d of %d tests passed\n", passed, len(ids))
+	}
+	if unchecked > 0 {
+		fmt.Printf(" %d tests have no answer (UNCHECKED)\n", unchecked)
+	}
+}
+
+// Watch runs the tests every time the work file or the session files change,
+// until interrupted.
+func Watch(config GocfConfig, opts TestOptions) {
+	n, err := newNotifier([]string{filepath.Dir(config.WorkFile), config.SessionDir})
+	if err != nil {
+		fmt.Println("File notifications not available (" + err.Error() + "), polling for changes")
+	}
+	for {
+		state := watchedFiles(config)
+		watchRun(config, opts)
+		// force the build only once
+		opts.Rebuild = false
+		fmt.Println("Watching for changes, press Ctrl+C to stop...")
+		for sameFiles(state, watchedFiles(config)) {
+			if n == nil {
+				time.Sleep(watchPollInterval)
+				continue
+			}
+			if err := n.wait(); err != nil {
+				fmt.Println("File notifications failed (" + err.Error() + "), polling for changes")
+				n = nil
+				continue
+			}
+			time.Sleep(watchSettleDelay)
+		}
+	}
+}
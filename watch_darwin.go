@@ -0,0 +1,13 @@
+package main
+
+import "errors"
+
+type notifier struct{}
+
+func newNotifier(dirs []string) (*notifier, error) {
+	return nil, errors.New("not supported on this platform")
+}
+
+func (n *notifier) wait() error {
+	return nil
+}
@@ -0,0 +1,36 @@
+package main
+
+import "syscall"
+
+// notifier wakes up on changes to a set of directories, using inotify.
+type notifier struct {
+	fd  int
+	buf []byte
+}
+
+func newNotifier(dirs []string) (*notifier, error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC)
+	if err != nil {
+		return nil, err
+	}
+	// editors may replace files rather than write them, so watch the directories
+	mask := uint32(syscall.IN_CLOSE_WRITE | syscall.IN_MODIFY | syscall.IN_CREATE | syscall.IN_DELETE |
+		syscall.IN_MOVED_FROM | syscall.IN_MOVED_TO)
+	for _, dir := range dirs {
+		if _, err := syscall.InotifyAddWatch(fd, dir, mask); err != nil {
+			syscall.Close(fd)
+			return nil, err
+		}
+	}
+	return &notifier{fd: fd, buf: make([]byte, 64*1024)}, nil
+}
+
+// wait blocks until something changes in the watched directories.
+func (n *notifier) wait() error {
+	for {
+		_, err := syscall.Read(n.fd, n.buf)
+		if err != syscall.EINTR {
+			return err
+		}
+	}
+}